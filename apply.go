@@ -0,0 +1,740 @@
+package todoist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// These errors can happen when applying queued commands locally. They're only logged, the servers have the last
+// word on whether a command is valid.
+var (
+	errNotFound           = errors.New("entity not found")
+	errUnknownTemporaryID = errors.New("unknown temporary id")
+	errUnknownCommand     = errors.New("unknown command type")
+)
+
+// The client keeps two copies of the data. One is the data as last known from the servers (clientData.Items and
+// similar), the other is the view (Client.view), which is what all lookups and searches use. The view is obtained
+// by applying all queued commands to the former, so that local changes are visible right away, without waiting
+// for a push and a pull. Entities created locally appear in the view with negative ids until their commands are
+// pushed and the servers assign permanent ids. If a command fails, rolling it back amounts to dropping it from the
+// queue and computing the view again.
+
+// clone returns a copy of the data with new maps but the same entities. That's fine because entities are never
+// modified in place.
+func (data *clientData) clone() *clientData {
+	view := &clientData{
+		SyncToken:    data.SyncToken,
+		User:         data.User,
+		Items:        make(map[int64]*Item, len(data.Items)),
+		Labels:       make(map[int64]*Label, len(data.Labels)),
+		Notes:        make(map[int64]*Note, len(data.Notes)),
+		ProjectNotes: make(map[int64]*Note, len(data.ProjectNotes)),
+		Projects:     make(map[int64]*Project, len(data.Projects)),
+		Sections:     make(map[int64]*Section, len(data.Sections)),
+		Reminders:    make(map[int64]*Reminder, len(data.Reminders)),
+		Filters:      make(map[int64]*Filter, len(data.Filters)),
+		Archive:      make(map[int64]*Item, len(data.Archive)),
+		Completed:    make(map[int64]*CompletedItem, len(data.Completed)),
+	}
+	for id, item := range data.Items {
+		view.Items[id] = item
+	}
+	for id, label := range data.Labels {
+		view.Labels[id] = label
+	}
+	for id, note := range data.Notes {
+		view.Notes[id] = note
+	}
+	for id, note := range data.ProjectNotes {
+		view.ProjectNotes[id] = note
+	}
+	for id, project := range data.Projects {
+		view.Projects[id] = project
+	}
+	for id, section := range data.Sections {
+		view.Sections[id] = section
+	}
+	for id, reminder := range data.Reminders {
+		view.Reminders[id] = reminder
+	}
+	for id, filter := range data.Filters {
+		view.Filters[id] = filter
+	}
+	for id, item := range data.Archive {
+		view.Archive[id] = item
+	}
+	for id, item := range data.Completed {
+		view.Completed[id] = item
+	}
+	return view
+}
+
+// refreshView recomputes the view from scratch. Must be called with c.mu held for writing.
+func (c *Client) refreshView() {
+	view := c.data.clone()
+	for _, cmd := range c.data.Commands {
+		// Failed commands are rolled back, see PushError.
+		if cmd.Err == nil {
+			c.applyLogged(view, cmd)
+		}
+	}
+	c.view = view
+}
+
+// queuedTempIDs returns the known permanent ids of the temporary ids referenced by queued commands. Must be called
+// with c.mu held.
+func (c *Client) queuedTempIDs() map[string]int64 {
+	ids := make(map[string]int64)
+	for _, cmd := range c.data.Commands {
+		var b []byte
+		if raw, ok := cmd.Args.(json.RawMessage); ok {
+			b = raw
+		} else if marshaled, err := json.Marshal(cmd.Args); err == nil {
+			b = marshaled
+		}
+		var args map[string]json.RawMessage
+		if err := json.Unmarshal(b, &args); err != nil {
+			continue
+		}
+		for _, key := range idArgs {
+			for _, tid := range temporaryIDs(args[key]) {
+				if id, ok := c.t2p[tid]; ok {
+					ids[tid] = id
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// temporaryIDs returns the temporary ids in the given JSON value, which can be an id or an array of ids.
+func temporaryIDs(value json.RawMessage) []string {
+	var tid string
+	if err := json.Unmarshal(value, &tid); err == nil {
+		return []string{tid}
+	}
+	var values []json.RawMessage
+	if err := json.Unmarshal(value, &values); err != nil {
+		return nil
+	}
+	var tids []string
+	for _, v := range values {
+		tids = append(tids, temporaryIDs(v)...)
+	}
+	return tids
+}
+
+// localID returns the id to use in the client's data for an entity that has the given temporary id: the
+// permanent id if known, otherwise the negative id allocated when the command that creates the entity was queued.
+// Must be called with c.mu held.
+func (c *Client) localID(temporaryID string) (int64, bool) {
+	if id, ok := c.t2p[temporaryID]; ok {
+		return id, true
+	}
+	id, ok := c.data.LocalIDs[temporaryID]
+	return id, ok
+}
+
+// allocateLocalID assigns a negative id to the given temporary id. Ids are never reused, not even after
+// forgetLocalIDs, because windows and failed commands may still hold on to old ones. Must be called with c.mu held
+// for writing.
+func (c *Client) allocateLocalID(temporaryID string) {
+	// State files written before LastLocalID existed may hold lower ids.
+	for _, id := range c.data.LocalIDs {
+		if id < c.data.LastLocalID {
+			c.data.LastLocalID = id
+		}
+	}
+	c.data.LastLocalID--
+	c.data.LocalIDs[temporaryID] = c.data.LastLocalID
+}
+
+// forgetLocalIDs drops the local ids of entities whose permanent id is now known. Must be called with c.mu held
+// for writing.
+func (c *Client) forgetLocalIDs() {
+	for tid := range c.data.LocalIDs {
+		if _, ok := c.t2p[tid]; ok {
+			delete(c.data.LocalIDs, tid)
+		}
+	}
+}
+
+func (c *Client) applyLogged(data *clientData, cmd *command) {
+	if err := c.apply(data, cmd); err != nil {
+		log.WithFields(log.Fields{
+			"type":  cmd.Type,
+			"uuid":  cmd.UUID,
+			"cause": err,
+		}).Warning("Could not apply command locally")
+	}
+}
+
+// Keys of command arguments that reference other entities, and can therefore hold temporary ids.
+var idArgs = []string{"id", "item_id", "labels", "parent_id", "project_id", "section_id"}
+
+// args returns the command arguments in JSON form, with temporary ids replaced by local or permanent ids.
+func (c *Client) args(cmd *command) (map[string]json.RawMessage, error) {
+	var b []byte
+	if raw, ok := cmd.Args.(json.RawMessage); ok {
+		b = raw
+	} else {
+		var err error
+		if b, err = json.Marshal(cmd.Args); err != nil {
+			return nil, err
+		}
+	}
+	var args map[string]json.RawMessage
+	if err := json.Unmarshal(b, &args); err != nil {
+		return nil, err
+	}
+	for _, key := range idArgs {
+		if value, ok := args[key]; ok {
+			resolved, err := c.resolveIDs(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			args[key] = resolved
+		}
+	}
+	return args, nil
+}
+
+// resolveIDs replaces temporary ids in the given JSON value, which can be an id or an array of ids.
+func (c *Client) resolveIDs(value json.RawMessage) (json.RawMessage, error) {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 {
+		return value, nil
+	}
+	switch value[0] {
+	case '"':
+		var tid string
+		if err := json.Unmarshal(value, &tid); err != nil {
+			return nil, err
+		}
+		id, ok := c.localID(tid)
+		if !ok {
+			return nil, fmt.Errorf("temporary id %q: %w", tid, errUnknownTemporaryID)
+		}
+		return json.Marshal(id)
+	case '[':
+		var values []json.RawMessage
+		if err := json.Unmarshal(value, &values); err != nil {
+			return nil, err
+		}
+		for i := range values {
+			resolved, err := c.resolveIDs(values[i])
+			if err != nil {
+				return nil, err
+			}
+			values[i] = resolved
+		}
+		return json.Marshal(values)
+	default:
+		return value, nil
+	}
+}
+
+// overlay copies src into dst, replacing the properties present in args. Both src and dst are pointers to
+// entities (e.g., *Item) and dst should point to a zero value, so that it doesn't share memory with src.
+func overlay(src interface{}, args map[string]json.RawMessage, dst interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(b, &props); err != nil {
+		return err
+	}
+	for k, v := range args {
+		if k != "id" {
+			props[k] = v
+		}
+	}
+	if b, err = json.Marshal(props); err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// argsID extracts the (resolved) id of the entity a command refers to.
+func argsID(args map[string]json.RawMessage) (int64, error) {
+	var id int64
+	if err := json.Unmarshal(args["id"], &id); err != nil {
+		return 0, fmt.Errorf("id: %w", err)
+	}
+	return id, nil
+}
+
+// apply executes the command against the given data, which is either the client's view or its copy of the server
+// data. Must be called with c.mu held for writing.
+func (c *Client) apply(data *clientData, cmd *command) error {
+	args, err := c.args(cmd)
+	if err != nil {
+		return err
+	}
+	var newID int64
+	if cmd.TempID != "" {
+		var ok bool
+		if newID, ok = c.localID(cmd.TempID); !ok {
+			return fmt.Errorf("temporary id %q: %w", cmd.TempID, errUnknownTemporaryID)
+		}
+	}
+	switch cmd.Type {
+	case itemAdd:
+		var item Item
+		if err := overlay(&Item{ID: newID}, args, &item); err != nil {
+			return err
+		}
+		data.Items[item.ID] = c.previewDue(&item)
+	case itemUpdate:
+		if err := data.patchItem(args); err != nil {
+			return err
+		}
+		if _, ok := args["due"]; ok {
+			id, _ := argsID(args)
+			data.Items[id] = c.previewDue(data.Items[id])
+		}
+	case itemMove:
+		return data.moveItem(args)
+	case itemDelete:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		data.deleteItem(id)
+	case itemClose:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		// Recurring items are not completed, the servers move them to the next occurrence. So do we, if we
+		// understand the due string.
+		if item, ok := data.Items[id]; ok && item.Due != nil && item.Due.IsRecurring {
+			if args, ok := c.nextDueArgs(item); ok {
+				return data.patchItem(args)
+			}
+			return nil
+		}
+		// Sub-tasks are completed along with their parent.
+		for _, id := range append(data.descendants(id), id) {
+			if err := data.patchItem(map[string]json.RawMessage{"id": jsonInt(id), "checked": json.RawMessage("1")}); err != nil {
+				return err
+			}
+			if c.archiveCompleted {
+				data.Archive[id] = data.Items[id]
+				delete(data.Items, id)
+			}
+		}
+	case itemUncomplete:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		for cid, completed := range data.Completed {
+			if completed.ItemID == id {
+				delete(data.Completed, cid)
+			}
+		}
+		if item, ok := data.Archive[id]; ok {
+			delete(data.Archive, id)
+			data.Items[id] = item
+		}
+		return data.patchItem(map[string]json.RawMessage{"id": args["id"], "checked": json.RawMessage("0"), "date_completed": json.RawMessage(`""`)})
+	case itemReorder:
+		return data.reorder(args, "items", data.patchItem)
+	case labelAdd:
+		var label Label
+		if err := overlay(&Label{ID: newID}, args, &label); err != nil {
+			return err
+		}
+		data.Labels[label.ID] = &label
+	case labelUpdate:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		stale, ok := data.Labels[id]
+		if !ok {
+			return fmt.Errorf("label %d: %w", id, errNotFound)
+		}
+		var label Label
+		if err := overlay(stale, args, &label); err != nil {
+			return err
+		}
+		data.Labels[id] = &label
+	case labelDelete:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		delete(data.Labels, id)
+	case projectAdd:
+		var project Project
+		if err := overlay(&Project{ID: newID}, args, &project); err != nil {
+			return err
+		}
+		data.Projects[project.ID] = &project
+	case projectUpdate:
+		return data.patchProject(args)
+	case projectArchive:
+		return data.patchProject(map[string]json.RawMessage{"id": args["id"], "is_archived": json.RawMessage("1")})
+	case projectUnarchive:
+		return data.patchProject(map[string]json.RawMessage{"id": args["id"], "is_archived": json.RawMessage("0")})
+	case projectDelete:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		data.deleteProject(id)
+	case projectReorder:
+		return data.reorder(args, "projects", data.patchProject)
+	case projectMove:
+		return data.patchProject(map[string]json.RawMessage{"id": args["id"], "parent_id": args["parent_id"]})
+	case sectionAdd:
+		var section Section
+		if err := overlay(&Section{ID: newID}, args, &section); err != nil {
+			return err
+		}
+		data.Sections[section.ID] = &section
+	case sectionUpdate:
+		return data.patchSection(args)
+	case sectionMove:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		if err := data.patchSection(map[string]json.RawMessage{"id": args["id"], "project_id": args["project_id"]}); err != nil {
+			return err
+		}
+		for iid, item := range data.Items {
+			if item.SectionID == id {
+				if err := data.patchItem(map[string]json.RawMessage{"id": jsonInt(iid), "project_id": args["project_id"]}); err != nil {
+					return err
+				}
+			}
+		}
+	case sectionReorder:
+		return data.reorder(args, "sections", data.patchSection)
+	case sectionArchive:
+		return data.patchSection(map[string]json.RawMessage{"id": args["id"], "is_archived": json.RawMessage("true")})
+	case sectionDelete:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		data.deleteSection(id)
+	case reminderAdd:
+		var reminder Reminder
+		if err := overlay(&Reminder{ID: newID}, args, &reminder); err != nil {
+			return err
+		}
+		data.Reminders[reminder.ID] = &reminder
+	case reminderUpdate:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		stale, ok := data.Reminders[id]
+		if !ok {
+			return fmt.Errorf("reminder %d: %w", id, errNotFound)
+		}
+		var reminder Reminder
+		if err := overlay(stale, args, &reminder); err != nil {
+			return err
+		}
+		data.Reminders[id] = &reminder
+	case reminderDelete:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		delete(data.Reminders, id)
+	case filterAdd:
+		var filter Filter
+		if err := overlay(&Filter{ID: newID}, args, &filter); err != nil {
+			return err
+		}
+		data.Filters[filter.ID] = &filter
+	case filterUpdate:
+		return data.patchFilter(args)
+	case filterDelete:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		delete(data.Filters, id)
+	case filterUpdateOrders:
+		var orders map[int64]int
+		if err := json.Unmarshal(args["id_order_mapping"], &orders); err != nil {
+			return fmt.Errorf("id_order_mapping: %w", err)
+		}
+		for id, order := range orders {
+			if err := data.patchFilter(map[string]json.RawMessage{"id": jsonInt(id), "item_order": jsonInt(int64(order))}); err != nil {
+				return err
+			}
+		}
+	case noteAdd:
+		var note Note
+		if err := overlay(&Note{ID: newID, Posted: time.Now().UTC().Format(time.RFC3339)}, args, &note); err != nil {
+			return err
+		}
+		if item, ok := data.Items[note.ItemID]; ok {
+			note.ProjectID = item.ProjectID
+		}
+		data.Notes[note.ID] = &note
+	case noteUpdate:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		stale, ok := data.Notes[id]
+		if !ok {
+			return fmt.Errorf("note %d: %w", id, errNotFound)
+		}
+		var note Note
+		if err := overlay(stale, args, &note); err != nil {
+			return err
+		}
+		data.Notes[id] = &note
+	case noteDelete:
+		id, err := argsID(args)
+		if err != nil {
+			return err
+		}
+		delete(data.Notes, id)
+		delete(data.ProjectNotes, id)
+	default:
+		return fmt.Errorf("%q: %w", cmd.Type, errUnknownCommand)
+	}
+	return nil
+}
+
+// previewDue fills in the date of a due date set as a string (see ItemPatch.WithDueString), which the servers
+// will parse, with what ParseDueString makes of it, if anything. Must be called with c.mu held.
+func (c *Client) previewDue(item *Item) *Item {
+	if item.Due == nil || item.Due.Date != "" || item.Due.String == "" {
+		return item
+	}
+	due, err := ParseDueString(item.Due.String, time.Now().In(c.locationLocked()))
+	if err != nil {
+		return item
+	}
+	if item.Due.Lang != "" {
+		due.Lang = item.Due.Lang
+	}
+	previewed := *item
+	previewed.Due = &due
+	return &previewed
+}
+
+func (data *clientData) patchItem(args map[string]json.RawMessage) error {
+	id, err := argsID(args)
+	if err != nil {
+		return err
+	}
+	stale, ok := data.Items[id]
+	if !ok {
+		return fmt.Errorf("item %d: %w", id, errNotFound)
+	}
+	var item Item
+	if err := overlay(stale, args, &item); err != nil {
+		return err
+	}
+	data.Items[id] = &item
+	return nil
+}
+
+// moveItem applies an item_move command. The destination is either a project or a section, in which case the item
+// moves to its top level, or a parent item, in which case the item joins the parent's project and section.
+// Sub-tasks follow the item.
+func (data *clientData) moveItem(args map[string]json.RawMessage) error {
+	id, err := argsID(args)
+	if err != nil {
+		return err
+	}
+	patch := map[string]json.RawMessage{
+		"id":         args["id"],
+		"project_id": args["project_id"],
+		"parent_id":  json.RawMessage("0"),
+		"section_id": json.RawMessage("0"),
+	}
+	if sectionID, ok := argsInt(args, "section_id"); ok {
+		section, ok := data.Sections[sectionID]
+		if !ok {
+			return fmt.Errorf("section %d: %w", sectionID, errNotFound)
+		}
+		patch["project_id"] = jsonInt(section.ProjectID)
+		patch["section_id"] = jsonInt(sectionID)
+	}
+	if parentID, ok := argsInt(args, "parent_id"); ok {
+		parent, ok := data.Items[parentID]
+		if !ok {
+			return fmt.Errorf("parent item %d: %w", parentID, errNotFound)
+		}
+		patch["project_id"] = jsonInt(parent.ProjectID)
+		patch["parent_id"] = jsonInt(parentID)
+		patch["section_id"] = jsonInt(parent.SectionID)
+	}
+	if err := data.patchItem(patch); err != nil {
+		return err
+	}
+	for _, d := range data.descendants(id) {
+		update := map[string]json.RawMessage{
+			"id":         jsonInt(d),
+			"project_id": patch["project_id"],
+			"section_id": patch["section_id"],
+		}
+		if err := data.patchItem(update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// descendants lists the sub-tasks of the given item, their sub-tasks, and so on.
+func (data *clientData) descendants(id int64) []int64 {
+	var ids []int64
+	for cid, item := range data.Items {
+		if item.ParentID == id && cid != id {
+			ids = append(ids, cid)
+			ids = append(ids, data.descendants(cid)...)
+		}
+	}
+	return ids
+}
+
+// argsInt extracts an integer argument, e.g., an id.
+func argsInt(args map[string]json.RawMessage, key string) (int64, bool) {
+	var value int64
+	if raw, ok := args[key]; ok && json.Unmarshal(raw, &value) == nil {
+		return value, true
+	}
+	return 0, false
+}
+
+func jsonInt(value int64) json.RawMessage {
+	return json.RawMessage(strconv.FormatInt(value, 10))
+}
+
+func (data *clientData) patchSection(args map[string]json.RawMessage) error {
+	id, err := argsID(args)
+	if err != nil {
+		return err
+	}
+	stale, ok := data.Sections[id]
+	if !ok {
+		return fmt.Errorf("section %d: %w", id, errNotFound)
+	}
+	var section Section
+	if err := overlay(stale, args, &section); err != nil {
+		return err
+	}
+	data.Sections[id] = &section
+	return nil
+}
+
+func (data *clientData) patchFilter(args map[string]json.RawMessage) error {
+	id, err := argsID(args)
+	if err != nil {
+		return err
+	}
+	stale, ok := data.Filters[id]
+	if !ok {
+		return fmt.Errorf("filter %d: %w", id, errNotFound)
+	}
+	var filter Filter
+	if err := overlay(stale, args, &filter); err != nil {
+		return err
+	}
+	data.Filters[id] = &filter
+	return nil
+}
+
+func (data *clientData) patchProject(args map[string]json.RawMessage) error {
+	id, err := argsID(args)
+	if err != nil {
+		return err
+	}
+	stale, ok := data.Projects[id]
+	if !ok {
+		return fmt.Errorf("project %d: %w", id, errNotFound)
+	}
+	var project Project
+	if err := overlay(stale, args, &project); err != nil {
+		return err
+	}
+	data.Projects[id] = &project
+	return nil
+}
+
+// reorder applies a reorder command, whose args map the entity type to a list of (id, child_order) pairs.
+func (data *clientData) reorder(args map[string]json.RawMessage, entity string, patch func(map[string]json.RawMessage) error) error {
+	var assignments []map[string]json.RawMessage
+	if err := json.Unmarshal(args[entity], &assignments); err != nil {
+		return fmt.Errorf("%s: %w", entity, err)
+	}
+	for _, a := range assignments {
+		if err := patch(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (data *clientData) deleteItem(id int64) {
+	// Sub-tasks are deleted along with their parent.
+	for _, id := range append(data.descendants(id), id) {
+		delete(data.Items, id)
+		for nid, note := range data.Notes {
+			if note.ItemID == id {
+				delete(data.Notes, nid)
+			}
+		}
+		for rid, reminder := range data.Reminders {
+			if reminder.ItemID == id {
+				delete(data.Reminders, rid)
+			}
+		}
+	}
+}
+
+func (data *clientData) deleteSection(id int64) {
+	delete(data.Sections, id)
+	for iid, item := range data.Items {
+		if item.SectionID == id {
+			data.deleteItem(iid)
+		}
+	}
+}
+
+func (data *clientData) deleteProject(id int64) {
+	delete(data.Projects, id)
+	// Sub-projects are deleted along with their parent.
+	for pid, project := range data.Projects {
+		if project.ParentID == id {
+			data.deleteProject(pid)
+		}
+	}
+	for sid, section := range data.Sections {
+		if section.ProjectID == id {
+			delete(data.Sections, sid)
+		}
+	}
+	for iid, item := range data.Items {
+		if item.ProjectID == id {
+			data.deleteItem(iid)
+		}
+	}
+	for nid, note := range data.ProjectNotes {
+		if note.ProjectID == id {
+			delete(data.ProjectNotes, nid)
+		}
+	}
+}
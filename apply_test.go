@@ -0,0 +1,95 @@
+package todoist_test
+
+import (
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueuedCommandsAreAppliedLocally(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	labelTempID := c.QueueLabelAdd(todoist.NewLabelPatch(0).WithName("later"))
+	itemTempID := c.QueueItemAdd(todoist.NewItemPatch(0).WithProjectID(10).WithContent("third").
+		WithLabels(todoist.NewTemporaryID(labelTempID)))
+	c.QueueNoteAdd(todoist.NewNotePatch(0).WithItemID(todoist.NewTemporaryID(itemTempID)).WithContent("comment"))
+	c.QueueItemUpdate(todoist.NewItemPatch(1).WithContent("first, edited").WithDue(todoist.Due{Date: "2019-08-07"}))
+	c.QueueItemClose(2)
+
+	label := c.LabelByName("later")
+	require.NotNil(t, label)
+	assert.True(t, label.ID < 0)
+	items := c.SearchItems().WithContent("third").Results()
+	require.Len(t, items, 1)
+	assert.True(t, items[0].ID < 0)
+	assert.Equal(t, []int64{label.ID}, items[0].Labels)
+	assert.Equal(t, int64(10), items[0].ProjectID)
+	notes := c.SearchNotes().WithItemID(items[0].ID).Results()
+	require.Len(t, notes, 1)
+	assert.Equal(t, int64(10), notes[0].ProjectID)
+	item, _ := c.ItemByID(1)
+	assert.Equal(t, "first, edited", item.Content)
+	assert.Equal(t, "2019-08-07", item.Due.Date)
+	assert.Equal(t, []int64{20}, item.Labels)
+	item, _ = c.ItemByID(2)
+	assert.Equal(t, 1, item.Checked)
+
+	require.Nil(t, c.Push())
+	itemID, ok := c.PermanentID(itemTempID)
+	require.True(t, ok)
+	labelID, ok := c.PermanentID(labelTempID)
+	require.True(t, ok)
+	item, ok = c.ItemByID(itemID)
+	require.True(t, ok)
+	assert.Equal(t, "third", item.Content)
+	assert.Equal(t, []int64{labelID}, item.Labels)
+	assert.Len(t, c.SearchNotes().WithItemID(itemID).Results(), 1)
+	assert.Len(t, c.SearchItems().WithContent("third").Results(), 1)
+	item, _ = c.ItemByID(1)
+	assert.Equal(t, "first, edited", item.Content)
+}
+
+func TestFailedCommandsAreRolledBack(t *testing.T) {
+	fs := newFakeServer()
+	fs.failTypes = map[string]bool{"item_update": true}
+	defer fs.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	c.QueueItemUpdate(todoist.NewItemPatch(1).WithContent("edited"))
+	c.QueueProjectUpdate(todoist.NewProjectPatch(10).WithName("Renamed"))
+	item, _ := c.ItemByID(1)
+	assert.Equal(t, "edited", item.Content)
+
+	assert.NotNil(t, c.Push())
+	item, _ = c.ItemByID(1)
+	assert.Equal(t, "first", item.Content)
+	project, _ := c.ProjectByID(10)
+	assert.Equal(t, "Renamed", project.Name)
+}
+
+func TestLocalIDsAreNotReused(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	c.QueueItemAdd(todoist.NewItemPatch(0).WithProjectID(10).WithContent("third"))
+	items := c.SearchItems().WithContent("third").Results()
+	require.Len(t, items, 1)
+	first := items[0].ID
+	require.Nil(t, c.Push())
+
+	c.QueueItemAdd(todoist.NewItemPatch(0).WithProjectID(10).WithContent("fourth"))
+	items = c.SearchItems().WithContent("fourth").Results()
+	require.Len(t, items, 1)
+	assert.True(t, items[0].ID < first)
+}
@@ -0,0 +1,42 @@
+package todoist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// ArchivedProjects fetches the archived projects from their own endpoint, as the sync API only returns projects
+// archived after the last sync. The projects are also added to the client's data, so that they can be looked up
+// with ProjectByID and SearchProjects (see ProjectScan.WithIsArchived), and restored with QueueProjectUnarchive.
+func (c *Client) ArchivedProjects() ([]*Project, error) {
+	return c.ArchivedProjectsContext(context.Background())
+}
+
+// ArchivedProjectsContext is like ArchivedProjects, but the remote call is bound to the given context.
+func (c *Client) ArchivedProjectsContext(ctx context.Context) ([]*Project, error) {
+	endpoint, err := c.resolveEndpoint("projects/get_archived")
+	if err != nil {
+		return nil, fmt.Errorf("archived projects: %w", err)
+	}
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	data := make(url.Values)
+	data.Set("token", c.token)
+	b, err := c.post(ctx, "archived projects", endpoint, data)
+	if err != nil {
+		return nil, err
+	}
+	var projects []*Project
+	if err := json.Unmarshal(b, &projects); err != nil {
+		return nil, fmt.Errorf("archived projects, unmarshal: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, project := range projects {
+		c.updateProject(project)
+	}
+	c.refreshView()
+	return projects, nil
+}
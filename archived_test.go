@@ -0,0 +1,47 @@
+package todoist_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchivedProjects(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sync/v8/sync":
+			_, _ = fmt.Fprint(w, `{"sync_token": "t", "projects": [{"id": 10, "name": "Inbox"}]}`)
+		case "/sync/v8/projects/get_archived":
+			if r.FormValue("token") != "token" {
+				http.Error(w, "", http.StatusForbidden)
+				return
+			}
+			_, _ = fmt.Fprint(w, `[{"id": 11, "name": "Old", "is_archived": 1}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL+"/sync/v8/sync"))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	projects, err := c.ArchivedProjects()
+	require.Nil(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, "Old", projects[0].Name)
+	archived := c.SearchProjects().WithIsArchived(1).Results()
+	require.Len(t, archived, 1)
+	assert.Equal(t, int64(11), archived[0].ID)
+
+	c.QueueProjectUnarchive(11)
+	project, ok := c.ProjectByID(11)
+	require.True(t, ok)
+	assert.Equal(t, 0, project.IsArchived)
+	assert.Len(t, c.SearchProjects().WithIsArchived(0).Results(), 2)
+}
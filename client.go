@@ -1,21 +1,16 @@
 package todoist
 
 import (
-	"crypto/sha256"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
-	"os/user"
-	"path"
+	"sync"
 	"time"
 )
 
-// ErrCorrupted can be returned by Load.
-var ErrCorrupted = errors.New("local data is corrupted")
-
 type clientOption func(*Client) error
 
 // WithEndpoint is a client option to set the endpoint when building a client with NewClient. This is meant to be
@@ -27,6 +22,25 @@ func WithEndpoint(endpoint string) clientOption {
 	}
 }
 
+// WithHTTPClient is a client option to set the HTTP client used for all remote calls, e.g., to configure a
+// timeout, a proxy, or a custom transport. By default, http.DefaultClient is used.
+func WithHTTPClient(hc *http.Client) clientOption {
+	return func(c *Client) error {
+		c.httpClient = hc
+		return nil
+	}
+}
+
+// WithCompletedArchive is a client option to keep completed items apart from the others, in an archive that can
+// be searched with SearchArchive. Without this option, completed items are kept along with the others, and one
+// has to exclude them explicitly from searches, e.g., with ItemScan.WithChecked.
+func WithCompletedArchive() clientOption {
+	return func(c *Client) error {
+		c.archiveCompleted = true
+		return nil
+	}
+}
+
 // WithWireLog is a client option to be passed to NewClient in order to log all requests and responses to the
 // specified log file. Useful for debugging the client itself, shouldn't be needed in normal operation.
 func WithWireLog(pathname string) clientOption {
@@ -42,55 +56,122 @@ func WithWireLog(pathname string) clientOption {
 // clientData is quite similar to pull response, only it maintains maps instead of slices.
 // This is what the client will persist (Dump, Load).
 type clientData struct {
+	// The version of this structure, see migrations. It's the first property so that it's at the beginning of the
+	// state file.
+	Version int `json:"version"`
+
 	// This token is used for synchronization. It correlates one sync API call with the next. We will only receive
 	// entities that have changed since the previous time (according to this token) we have called the sync API.
 	SyncToken string `json:"sync_token"`
 
-	Items        map[int64]*Item    `json:"items"`
-	Labels       map[int64]*Label   `json:"labels"`
-	Notes        map[int64]*Note    `json:"notes"`
-	ProjectNotes map[int64]*Note    `json:"project_notes"`
-	Projects     map[int64]*Project `json:"projects"`
+	Items        map[int64]*Item     `json:"items"`
+	Labels       map[int64]*Label    `json:"labels"`
+	Notes        map[int64]*Note     `json:"notes"`
+	ProjectNotes map[int64]*Note     `json:"project_notes"`
+	Projects     map[int64]*Project  `json:"projects"`
+	Sections     map[int64]*Section  `json:"sections"`
+	Reminders    map[int64]*Reminder `json:"reminders"`
+	Filters      map[int64]*Filter   `json:"filters"`
+
+	// The user the API token belongs to, see Client.User.
+	User *User `json:"user,omitempty"`
+
+	// Completed items, if the client was created with WithCompletedArchive. See SearchArchive.
+	Archive map[int64]*Item `json:"archive,omitempty"`
+
+	// Completed items fetched with FetchCompleted, keyed by the id of the completion. See SearchCompleted.
+	Completed map[int64]*CompletedItem `json:"completed,omitempty"`
+
+	// Commands, such as changing an item's content, are queued here and flushed when the Push() method is called.
+	// They're persisted along with the rest of the data, so that changes made while offline aren't lost.
+	Commands []*command `json:"commands,omitempty"`
+
+	// Ids assigned to entities created by queued commands, keyed by the commands' temporary ids. See apply.go.
+	LocalIDs map[string]int64 `json:"local_ids,omitempty"`
+
+	// The last local id allocated, which only ever decreases. See allocateLocalID.
+	LastLocalID int64 `json:"last_local_id,omitempty"`
+
+	// Permanent ids of the temporary ids that queued commands reference, so that the commands can still be applied
+	// after Load. Only used by Dump and Load, see Client.t2p.
+	TempIDs map[string]int64 `json:"temp_ids,omitempty"`
 }
 
 // Client is a Todoist Sync API client, for the v8 API version. For more documentation on the API see
 // https://developer.todoist.com/sync/v8/.
+//
+// A Client is safe for concurrent use by multiple goroutines.
 type Client struct {
 	endpoint string
 
+	// Used for all remote calls. See WithHTTPClient.
+	httpClient *http.Client
+
+	// How to retry failed remote calls. See WithRetryPolicy.
+	retry RetryPolicy
+
+	// Whether to move completed items out of the items map. See WithCompletedArchive.
+	archiveCompleted bool
+
+	// Where to Load and Dump the state. See WithStore.
+	store Store
+
 	// The secret token to authenticate and authorize API calls.
 	token string
 
 	// If non-nil, log all requests and responses to this file, one per line, in JSON format.
 	wlog io.Writer
 
-	// Represents our cached contents.
+	// Serializes remote calls, such as Pull and Push, so that sync tokens and command batches are sent to and
+	// received from the servers one round trip at a time, and requests are logged one at a time (see wlog).
+	syncMu sync.Mutex
+
+	// Guards all the fields below. Entities in data are never modified in place, they're replaced, so that
+	// pointers handed out to callers (e.g., by ItemByID) can be read without holding the lock.
+	mu sync.RWMutex
+
+	// Represents our cached contents, as last synced with the servers, and the queued commands.
 	data *clientData
 
+	// The cached contents with the queued commands applied. All lookups and searches use this.
+	view *clientData
+
 	// Temporary id (client-generated UUID) to permanent id (server-generated int64).
 	t2p map[string]int64
 
-	// Commands, such as changing an item's content, are queued here and flushed when the Push() method is called.
-	commands []*command
-
 	lastPulled time.Time
+
+	// The timezone set with WithTimezone, if any, and the one from the user's settings, see Location.
+	location     *time.Location
+	userLocation *time.Location
 }
 
 // NewClient creates a new client authenticated and authorized by the given token.
 func NewClient(token string, opts ...clientOption) (*Client, error) {
 	var data clientData
+	data.Version = len(migrations)
 	data.SyncToken = "*"
 	data.Items = make(map[int64]*Item)
 	data.Labels = make(map[int64]*Label)
 	data.Notes = make(map[int64]*Note)
 	data.ProjectNotes = make(map[int64]*Note)
 	data.Projects = make(map[int64]*Project)
+	data.Sections = make(map[int64]*Section)
+	data.Reminders = make(map[int64]*Reminder)
+	data.Filters = make(map[int64]*Filter)
+	data.Archive = make(map[int64]*Item)
+	data.Completed = make(map[int64]*CompletedItem)
+	data.LocalIDs = make(map[string]int64)
 	c := &Client{
-		endpoint: "https://api.todoist.com/sync/v8/sync",
-		token:    token,
-		data:     &data,
-		t2p:      make(map[string]int64),
-		wlog:     ioutil.Discard,
+		endpoint:   "https://api.todoist.com/sync/v8/sync",
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+		store:      NewFileStore(""),
+		token:      token,
+		data:       &data,
+		view:       data.clone(),
+		t2p:        make(map[string]int64),
+		wlog:       ioutil.Discard,
 	}
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
@@ -100,83 +181,121 @@ func NewClient(token string, opts ...clientOption) (*Client, error) {
 	return c, nil
 }
 
-// Load loads the client state from the state files in lib/todoist/ in the user's home directory.
+// Load loads the client state from the client's store (see WithStore). The state includes commands that were
+// queued but not pushed successfully before the state was saved with Dump, and the next call to Push will send
+// them. Load replaces the client's in-memory state, so it should be called before queueing commands.
 func (c *Client) Load() error {
-	u, err := user.Current()
+	// Don't swap the data under the feet of an ongoing Push.
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	data, err := c.store.Load()
 	if err != nil {
 		return err
 	}
-	data, err := ioutil.ReadFile(path.Join(u.HomeDir, "lib/todoist/state.data"))
-	if err != nil {
+	var loaded clientData
+	if err := json.Unmarshal(data, &loaded); err != nil {
 		return err
 	}
-	savedSum, err := ioutil.ReadFile(path.Join(u.HomeDir, "lib/todoist/state.sum"))
-	if err != nil {
-		return err
+	if loaded.Version > len(migrations) {
+		return fmt.Errorf("state version %d: %w", loaded.Version, ErrUnsupportedVersion)
 	}
-	sum := sha256.Sum256(data)
-	if len(savedSum) != len(sum) {
-		return fmt.Errorf("length mismatch: %w", ErrCorrupted)
+	if loaded.Sections == nil {
+		loaded.Sections = make(map[int64]*Section)
 	}
-	for i := 0; i < len(sum); i++ {
-		if savedSum[i] != sum[i] {
-			return fmt.Errorf("checksum difference at byte %d: %w", i, ErrCorrupted)
-		}
+	if loaded.Reminders == nil {
+		loaded.Reminders = make(map[int64]*Reminder)
 	}
-	var loaded clientData
-	err = json.Unmarshal(data, &loaded)
-	if err == nil {
-		c.data = &loaded
+	if loaded.Filters == nil {
+		loaded.Filters = make(map[int64]*Filter)
+	}
+	if loaded.Archive == nil {
+		loaded.Archive = make(map[int64]*Item)
+	}
+	if loaded.Completed == nil {
+		loaded.Completed = make(map[int64]*CompletedItem)
 	}
-	return err
+	if loaded.LocalIDs == nil {
+		loaded.LocalIDs = make(map[string]int64)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t2p = loaded.TempIDs
+	if c.t2p == nil {
+		c.t2p = make(map[string]int64)
+	}
+	loaded.TempIDs = nil
+	c.data = &loaded
+	c.updateUser(loaded.User)
+	c.migrate()
+	c.refreshView()
+	return nil
 }
 
-// Dump saves the client's in-memory state to a pair of files in the lib/todoist/ directory within the user's
-// home directory.  The counterpart method to load the state is Load. This dump and load mechanism is present to
-// avoid  full syncs and do incremental syncs only, see https://developer.todoist.com/sync/v8/#sync for details. All
-// clients use the same state files, so state can be overridden if using more than one instance of the client.
+// Dump saves the client's in-memory state, including the commands that have not been pushed yet, to the client's
+// store (see WithStore). The counterpart method to load the state is Load. This dump and load mechanism is present
+// to avoid full syncs and do incremental syncs only, see https://developer.todoist.com/sync/v8/#sync for details.
+// Clients using the same store override each other's state, so use a different store for each instance.
 func (c *Client) Dump() error {
-	data, err := json.Marshal(c.data)
+	c.mu.RLock()
+	dumped := *c.data
+	dumped.TempIDs = c.queuedTempIDs()
+	data, err := json.Marshal(&dumped)
+	c.mu.RUnlock()
 	if err != nil {
 		return err
 	}
-	sum := sha256.Sum256(data)
-	u, err := user.Current()
-	if err != nil {
-		return err
-	}
-	if err := ioutil.WriteFile(path.Join(u.HomeDir, "lib/todoist/state.data"), data, 0600); err != nil {
-		return err
-	}
-	if err := ioutil.WriteFile(path.Join(u.HomeDir, "lib/todoist/state.sum"), sum[:], 0600); err != nil {
-		return err
-	}
-	return nil
+	return c.store.Save(data)
 }
 
 // ItemByID looks up the item by id in the client's data (no remote call is made). The item should be treated as
 // read-only. To update an item's property, the workflow is to enqueue commands to update the item, e.g., using
-// ItemPatch and QueueItemUpdate, then Push the commands to the servers, and finally Pull() the updated state.
+// ItemPatch and QueueItemUpdate, then Push the commands to the servers. Queued commands are reflected in the
+// client's data right away, so, for example, an item created with QueueItemAdd can be looked up with a negative
+// id until its command is pushed and the item gets a permanent id.
 func (c *Client) ItemByID(id int64) (*Item, bool) {
-	i, ok := c.data.Items[id]
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	i, ok := c.view.Items[id]
 	return i, ok
 }
 
 // ProjectByID is analogous to ItemByID.
 func (c *Client) ProjectByID(id int64) (*Project, bool) {
-	p, ok := c.data.Projects[id]
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	p, ok := c.view.Projects[id]
 	return p, ok
 }
 
+// SectionByID is analogous to ItemByID.
+func (c *Client) SectionByID(id int64) (*Section, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s, ok := c.view.Sections[id]
+	return s, ok
+}
+
+// FilterByID is analogous to ItemByID.
+func (c *Client) FilterByID(id int64) (*Filter, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	f, ok := c.view.Filters[id]
+	return f, ok
+}
+
 // LabelByID is analogous to ItemByID.
 func (c *Client) LabelByID(id int64) (*Label, bool) {
-	l, ok := c.data.Labels[id]
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	l, ok := c.view.Labels[id]
 	return l, ok
 }
 
 // LabelByName is analogous to ItemByID.
 func (c *Client) LabelByName(name string) *Label {
-	for _, l := range c.data.Labels {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, l := range c.view.Labels {
 		if l.Name == name {
 			return l
 		}
@@ -186,7 +305,9 @@ func (c *Client) LabelByName(name string) *Label {
 
 // NoteByID is analogous to ItemByID.
 func (c *Client) NoteByID(id int64) (*Note, bool) {
-	n, ok := c.data.Notes[id]
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	n, ok := c.view.Notes[id]
 	return n, ok
 }
 
@@ -196,42 +317,130 @@ func (c *Client) NoteByID(id int64) (*Note, bool) {
 // and this mapping is returned in the response to the push API call. The client maintains such mapping and uses
 // it to implement this method. See https://developer.todoist.com/sync/v8/#sync for details.
 func (c *Client) PermanentID(temporaryID string) (permanentID int64, found bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	permanentID, found = c.t2p[temporaryID]
 	return
 }
 
+// The update methods below must be called with c.mu held for writing, and followed by a call to refreshView. They
+// replace rather than modify the cached entities, see the comment on Client.mu. Deleted entities are dropped, and
+// completed items are moved to the archive if the client was created with WithCompletedArchive.
+
 func (c *Client) updateItem(current *Item) {
-	stale, ok := c.ItemByID(current.ID)
-	if ok {
-		*stale = *current
-	} else {
+	switch {
+	case current.IsDeleted != 0:
+		c.data.deleteItem(current.ID)
+		delete(c.data.Archive, current.ID)
+	case current.Checked != 0 && c.archiveCompleted:
+		delete(c.data.Items, current.ID)
+		c.data.Archive[current.ID] = current
+	default:
+		delete(c.data.Archive, current.ID)
 		c.data.Items[current.ID] = current
 	}
 }
 
 func (c *Client) updateProject(current *Project) {
-	stale, ok := c.ProjectByID(current.ID)
-	if ok {
-		*stale = *current
+	if current.IsDeleted != 0 {
+		delete(c.data.Projects, current.ID)
 	} else {
 		c.data.Projects[current.ID] = current
 	}
 }
 
+func (c *Client) updateSection(current *Section) {
+	if current.IsDeleted {
+		delete(c.data.Sections, current.ID)
+	} else {
+		c.data.Sections[current.ID] = current
+	}
+}
+
+func (c *Client) updateReminder(current *Reminder) {
+	if current.IsDeleted != 0 {
+		delete(c.data.Reminders, current.ID)
+	} else {
+		c.data.Reminders[current.ID] = current
+	}
+}
+
+func (c *Client) updateFilter(current *Filter) {
+	if current.IsDeleted != 0 {
+		delete(c.data.Filters, current.ID)
+	} else {
+		c.data.Filters[current.ID] = current
+	}
+}
+
 func (c *Client) updateLabel(current *Label) {
-	stale, ok := c.LabelByID(current.ID)
-	if ok {
-		*stale = *current
+	if current.IsDeleted != 0 {
+		delete(c.data.Labels, current.ID)
 	} else {
 		c.data.Labels[current.ID] = current
 	}
 }
 
 func (c *Client) updateNote(current *Note) {
-	stale, ok := c.NoteByID(current.ID)
-	if ok {
-		*stale = *current
+	if current.IsDeleted != 0 {
+		delete(c.data.Notes, current.ID)
 	} else {
 		c.data.Notes[current.ID] = current
 	}
 }
+
+// Compact drops deleted entities from the client's data, as well as notes whose item is gone, and moves completed
+// items to the archive if the client was created with WithCompletedArchive. Pull does the same for the entities it
+// receives, so this is only useful for data saved by older versions of this package. Call Dump afterwards to
+// compact the state files too.
+func (c *Client) Compact() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.compact()
+	c.refreshView()
+}
+
+// compact implements Compact. Must be called with c.mu held for writing.
+func (c *Client) compact() {
+	for _, item := range c.data.Items {
+		c.updateItem(item)
+	}
+	for _, project := range c.data.Projects {
+		c.updateProject(project)
+	}
+	for _, section := range c.data.Sections {
+		c.updateSection(section)
+	}
+	for _, reminder := range c.data.Reminders {
+		c.updateReminder(reminder)
+	}
+	for _, filter := range c.data.Filters {
+		c.updateFilter(filter)
+	}
+	for _, label := range c.data.Labels {
+		c.updateLabel(label)
+	}
+	for _, note := range c.data.Notes {
+		c.updateNote(note)
+	}
+	c.data.dropDanglingNotes()
+}
+
+// dropDanglingNotes drops the notes and reminders of items that were deleted.
+func (data *clientData) dropDanglingNotes() {
+	for id, reminder := range data.Reminders {
+		_, ok := data.Items[reminder.ItemID]
+		if _, archived := data.Archive[reminder.ItemID]; !ok && !archived {
+			delete(data.Reminders, id)
+		}
+	}
+	for id, note := range data.Notes {
+		if note.ItemID == 0 {
+			continue
+		}
+		_, ok := data.Items[note.ItemID]
+		if _, archived := data.Archive[note.ItemID]; !ok && !archived {
+			delete(data.Notes, id)
+		}
+	}
+}
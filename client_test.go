@@ -0,0 +1,226 @@
+package todoist_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer is a minimal stand-in for the Todoist sync endpoint. Requests with commands are treated as pushes,
+// and every command is reported successful, unless its type is in failTypes; all other requests are treated as
+// pulls, and return a fixed inventory of resources, plus the items added by pushes, as full syncs do.
+type fakeServer struct {
+	*httptest.Server
+
+	failTypes map[string]bool // Must not be modified once the server is in use.
+
+	mu    sync.Mutex
+	added []string // Items added by pushes, in JSON.
+
+	pulls    int64
+	pushes   int64
+	commands int64 // Total number of commands received
+	nextID   int64
+}
+
+func newFakeServer() *fakeServer {
+	fs := &fakeServer{nextID: 1000}
+	fs.Server = httptest.NewServer(http.HandlerFunc(fs.serveHTTP))
+	return fs
+}
+
+func (fs *fakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if commands := r.PostForm.Get("commands"); commands != "" {
+		fs.push(w, commands)
+		return
+	}
+	fs.pull(w)
+}
+
+func (fs *fakeServer) push(w http.ResponseWriter, commands string) {
+	atomic.AddInt64(&fs.pushes, 1)
+	var parsed []struct {
+		Type   string `json:"type"`
+		UUID   string `json:"uuid"`
+		TempID string `json:"temp_id"`
+		Args   struct {
+			ProjectID json.RawMessage `json:"project_id"`
+			Content   string          `json:"content"`
+		} `json:"args"`
+	}
+	if err := json.Unmarshal([]byte(commands), &parsed); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	syncStatus := make(map[string]interface{})
+	tempIDMapping := make(map[string]int64)
+	atomic.AddInt64(&fs.commands, int64(len(parsed)))
+	for _, cmd := range parsed {
+		if fs.failTypes[cmd.Type] {
+			syncStatus[cmd.UUID] = map[string]interface{}{"error_code": 20, "error": "Invalid command"}
+			continue
+		}
+		syncStatus[cmd.UUID] = "ok"
+		if cmd.TempID != "" {
+			tempIDMapping[cmd.TempID] = atomic.AddInt64(&fs.nextID, 1)
+		}
+		if cmd.Type == "item_add" {
+			// Projects given by temporary id are not tracked.
+			var projectID int64
+			_ = json.Unmarshal(cmd.Args.ProjectID, &projectID)
+			item, _ := json.Marshal(map[string]interface{}{
+				"id":         tempIDMapping[cmd.TempID],
+				"project_id": projectID,
+				"content":    cmd.Args.Content,
+			})
+			fs.mu.Lock()
+			fs.added = append(fs.added, string(item))
+			fs.mu.Unlock()
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"sync_status":     syncStatus,
+		"temp_id_mapping": tempIDMapping,
+	})
+}
+
+func (fs *fakeServer) pull(w http.ResponseWriter) {
+	n := atomic.AddInt64(&fs.pulls, 1)
+	fs.mu.Lock()
+	var added string
+	for _, item := range fs.added {
+		added += ",\n" + item
+	}
+	fs.mu.Unlock()
+	_, _ = fmt.Fprintf(w, `{
+		"sync_token": "token-%d",
+		"items": [
+			{"id": 1, "project_id": 10, "labels": [20], "content": "first", "child_order": 1},
+			{"id": 2, "project_id": 10, "labels": [], "content": "second", "child_order": 2, "due": {"date": "2019-08-07"}}%s
+		],
+		"labels": [{"id": 20, "name": "next"}],
+		"notes": [{"id": 30, "item_id": 1, "project_id": 10, "content": "a note", "posted": "2019-08-07T10:00:00Z"}],
+		"project_notes": [],
+		"projects": [{"id": 10, "name": "Inbox", "child_order": 1}]
+	}`, n, added)
+}
+
+func TestClientPull(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	item, ok := c.ItemByID(1)
+	require.True(t, ok)
+	assert.Equal(t, "first", item.Content)
+	label := c.LabelByName("next")
+	require.NotNil(t, label)
+	assert.Equal(t, int64(20), label.ID)
+	assert.Len(t, c.SearchItems().WithProjectID(10).Results(), 2)
+	assert.Len(t, c.SearchNotes().WithItemID(1).Results(), 1)
+
+	// A second pull within a minute is a no-op.
+	require.Nil(t, c.Pull())
+	assert.Equal(t, int64(1), atomic.LoadInt64(&fs.pulls))
+}
+
+func TestClientPushFlushesQueue(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL))
+	require.Nil(t, err)
+	tempID := c.QueueItemAdd(todoist.NewItemPatch(0).WithContent("new"))
+	require.Nil(t, c.Push())
+	_, ok := c.PermanentID(tempID)
+	assert.True(t, ok)
+	// The queue was flushed, so this push sends no commands at all.
+	require.Nil(t, c.Push())
+	assert.Equal(t, int64(1), atomic.LoadInt64(&fs.commands))
+}
+
+// TestClientConcurrentUse is mostly useful when run with the race detector, e.g., go test -race.
+func TestClientConcurrentUse(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL))
+	require.Nil(t, err)
+
+	const workers, rounds = 8, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*rounds*2)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				switch (w + r) % 4 {
+				case 0:
+					errs <- c.Pull()
+				case 1:
+					tempID := c.QueueItemAdd(todoist.NewItemPatch(0).WithProjectID(10).WithContent("added"))
+					c.QueueNoteAdd(todoist.NewNotePatch(0).WithItemID(todoist.NewTemporaryID(tempID)).WithContent("note"))
+					errs <- c.Push()
+					_, _ = c.PermanentID(tempID)
+				case 2:
+					for _, item := range c.SearchItems().WithChecked(0).WithContent("i").Results() {
+						_, _ = c.ItemByID(item.ID)
+						_ = item.Content
+					}
+					for _, note := range c.SearchNotes().WithIsDeleted(0).Results() {
+						_ = note.Time()
+					}
+				case 3:
+					_ = c.LabelByName("next")
+					_ = c.SearchLabels().WithIsDeleted(0).Results()
+					for _, p := range c.SearchProjects().WithIsArchived(0).WithName("inbox").Results() {
+						_, _ = c.ProjectByID(p.ID)
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.Nil(t, err)
+	}
+	// Two items from the fake server plus those added by the workers.
+	assert.Len(t, c.SearchItems().Results(), 2+workers*rounds/4)
+}
+
+func TestClientContextCancellation(t *testing.T) {
+	unblock := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer ts.Close()
+	defer close(unblock)
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL), todoist.WithHTTPClient(ts.Client()))
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.True(t, errors.Is(c.PullContext(ctx), context.DeadlineExceeded))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	c.QueueItemClose(1)
+	assert.True(t, errors.Is(c.PushContext(ctx), context.Canceled))
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,13 +20,20 @@ import (
 type windowMode int
 
 const (
-	modeItem        windowMode = iota // /todo/items/$id
-	modeNewItem                       // /todo/items/new
-	modeProject                       // /todo/projects/$id
-	modeNewProject                    // /todo/projects/new
-	modeAllProjects                   // /todo/projects/all
-	modeSearch                        // /todo/search/$expr
-	modeCalendar                      // /todo/calendar
+	modeItem             windowMode = iota // /todo/items/$id
+	modeNewItem                            // /todo/items/new
+	modeProject                            // /todo/projects/$id
+	modeNewProject                         // /todo/projects/new
+	modeAllProjects                        // /todo/projects/all
+	modeSearch                             // /todo/search/$expr
+	modeCalendar                           // /todo/calendar
+	modeArchivedProjects                   // /todo/projects/archived
+	modeCompleted                          // /todo/completed
+	modeReminders                          // /todo/reminders
+	modeFilters                            // /todo/filters
+	modeToday                              // /todo/today
+	modeUpcoming                           // /todo/upcoming
+	modeOverdue                            // /todo/overdue
 )
 
 func (mode windowMode) String() string {
@@ -42,6 +52,20 @@ func (mode windowMode) String() string {
 		return "search"
 	case modeCalendar:
 		return "calendar"
+	case modeArchivedProjects:
+		return "archivedProjects"
+	case modeCompleted:
+		return "completed"
+	case modeReminders:
+		return "reminders"
+	case modeFilters:
+		return "filters"
+	case modeToday:
+		return "today"
+	case modeUpcoming:
+		return "upcoming"
+	case modeOverdue:
+		return "overdue"
 	default:
 		log.WithField("mode", int(mode)).Error("Missing mode string, returning as number")
 		return fmt.Sprintf("%d", int(mode))
@@ -65,6 +89,11 @@ type window struct {
 	// If false, sort by item.ItemOrder, as in the web app.  Only used for project mode, search mode, and all
 	// projects mode.
 	sortAlphabetically bool
+
+	// The items printed by the last load, for the modes where Put calls putItemLines. Guarded by mu, as windows
+	// are also loaded by other windows' event loops.
+	mu     sync.Mutex
+	listed []int64
 }
 
 // resetTag is used when a new window is created, or when transitioning a window from new item (project) mode to
@@ -73,19 +102,33 @@ func (w *window) resetTag() {
 	var tag string
 	switch w.mode {
 	case modeItem:
-		tag = " Projects Calendar New Get Put PutDel Complete Zap "
+		tag = " Projects Calendar New Get Put PutDel Preview Complete Zap "
 	case modeNewItem:
-		tag = " Projects Calendar Put PutDel "
+		tag = " Projects Calendar Put PutDel Preview "
 	case modeProject:
-		tag = " Projects Calendar New Get Put PutDel Sort Zap "
+		tag = " Projects Calendar New Get Put PutDel Sort Zap Resync "
 	case modeNewProject:
 		tag = " Projects Calendar Put PutDel "
 	case modeAllProjects:
-		tag = " Calendar New Get Put PutDel Sort Search Zap "
+		tag = " Calendar Agenda Upcoming Overdue Completed Archived Filters New Get Put PutDel Sort Search Zap Resync "
 	case modeSearch:
-		tag = " Projects Calendar Get Sort Search Zap "
+		tag = " Projects Calendar Get Put PutDel Sort Search Zap Resync "
 	case modeCalendar:
-		tag = " Projects Get Search Zap "
+		tag = " Projects Agenda Upcoming Overdue Get Put PutDel Search Zap Resync "
+	case modeToday:
+		tag = " Projects Calendar Upcoming Overdue Get Put PutDel Postpone Today Search Zap Resync "
+	case modeUpcoming:
+		tag = " Projects Calendar Agenda Overdue Get Put PutDel Postpone Today Search Zap Resync "
+	case modeOverdue:
+		tag = " Projects Calendar Agenda Upcoming Get Put PutDel Postpone Today Search Zap Resync "
+	case modeArchivedProjects:
+		tag = " Projects Calendar Get "
+	case modeCompleted:
+		tag = " Projects Calendar Get Uncomplete "
+	case modeReminders:
+		tag = " Projects Calendar Get "
+	case modeFilters:
+		tag = " Projects Calendar Get Put PutDel Zap "
 	}
 	_ = w.Ctl("cleartag")
 	_ = w.Fprintf("tag", tag)
@@ -145,6 +188,62 @@ func newAllProjectsWindow() {
 	go w.loop()
 }
 
+func newArchivedProjectsWindow() {
+	title := "/todo/projects/archived"
+	if acme.Show(title) != nil {
+		return
+	}
+	w := newWindow(title)
+	w.mode = modeArchivedProjects
+	w.resetTag()
+	go w.load()
+	go w.loop()
+}
+
+func newCompletedWindow() {
+	title := "/todo/completed"
+	if acme.Show(title) != nil {
+		return
+	}
+	w := newWindow(title)
+	w.mode = modeCompleted
+	w.resetTag()
+	go w.load()
+	go w.loop()
+}
+
+func newFiltersWindow() {
+	title := "/todo/filters"
+	if acme.Show(title) != nil {
+		return
+	}
+	w := newWindow(title)
+	w.mode = modeFilters
+	w.resetTag()
+	go w.load()
+	go w.loop()
+}
+
+// newRemindersWindow opens the window listing the reminders that went off, or reloads it if it's already open.
+func newRemindersWindow() {
+	title := "/todo/reminders"
+	all.Lock()
+	for _, w := range all.m {
+		if w.mode == modeReminders {
+			all.Unlock()
+			w.load()
+			_ = w.Ctl("show")
+			return
+		}
+	}
+	all.Unlock()
+	w := newWindow(title)
+	w.mode = modeReminders
+	w.resetTag()
+	go w.load()
+	go w.loop()
+}
+
 func newSearchWindow(expr string) {
 	title := "/todo/search/" + expr
 	if acme.Show(title) != nil {
@@ -215,24 +314,48 @@ func newCalendarWindow() {
 	go w.loop()
 }
 
+// newAgendaWindow opens the window of items due today, in the next days, or overdue, depending on the mode.
+func newAgendaWindow(mode windowMode) {
+	title := "/todo/" + mode.String()
+	if acme.Show(title) != nil {
+		return
+	}
+	w := newWindow(title)
+	w.mode = mode
+	w.resetTag()
+	go w.load()
+	go w.loop()
+}
+
 // Look is invoked via button-3 click in acme. We need to see if we can open other windows from the current
 // one, e.g., if text contains an item id or a project id. Should return true if we were able to handle
 // the action, otherwise return false to defer to other handlers (to, e.g., open a URL in the browser).
 func (w *window) Look(text string) bool {
 	switch w.mode {
-	case modeAllProjects:
+	case modeAllProjects, modeArchivedProjects:
 		if id, err := strconv.ParseInt(text, 10, 64); err == nil {
 			if _, ok := client.ProjectByID(id); ok {
 				newProjectWindow(id)
 				return true
 			}
 		}
+	case modeFilters:
+		if id, err := strconv.ParseInt(text, 10, 64); err == nil {
+			if filter, ok := client.FilterByID(id); ok {
+				newSearchWindow(filter.Query)
+				return true
+			}
+		}
+		if filters := client.SearchFilters().WithName(text).Results(); len(filters) != 0 {
+			newSearchWindow(filters[0].Query)
+			return true
+		}
 	case modeItem:
 		if projects := client.SearchProjects().WithName(text).Results(); len(projects) != 0 {
 			newProjectWindow(projects[0].ID)
 			return true
 		}
-	case modeProject, modeSearch, modeCalendar:
+	case modeProject, modeSearch, modeCalendar, modeCompleted, modeReminders, modeToday, modeUpcoming, modeOverdue:
 		id, err := strconv.ParseInt(text, 10, 64)
 		if err == nil {
 			if item, ok := client.ItemByID(id); ok {
@@ -249,27 +372,17 @@ func (w *window) load() {
 		w.Errf("load: pull: %v", err)
 	}
 	var buf bytes.Buffer
-	var err error
-	switch w.mode {
-	case modeNewItem:
-		err = printNewItemForProject(&buf, w.projectID)
-	case modeNewProject:
-		// Leave buffer empty.
-	case modeItem:
-		err = printItemByID(&buf, w.itemID)
-	case modeProject:
-		err = printProjectByID(&buf, w.projectID)
-	case modeSearch:
-		err = printSearch(&buf, w.expr)
-	case modeAllProjects:
-		err = printAllProjects(&buf)
-	case modeCalendar:
-		err = printCalendar(&buf)
+	err := w.print(&buf)
+	w.mu.Lock()
+	w.listed = nil
+	if err == nil {
+		w.listed = printedIDs(buf.String())
 	}
+	w.mu.Unlock()
 	w.Clear()
 	if err != nil {
 		_, _ = w.Write("body", []byte(err.Error()))
-	} else if w.mode != modeProject && w.mode != modeSearch {
+	} else if w.mode != modeProject && w.mode != modeSearch && w.mode != modeToday && w.mode != modeOverdue {
 		_, _ = w.Write("body", buf.Bytes())
 		_ = w.Ctl("clean")
 	} else {
@@ -286,6 +399,42 @@ func (w *window) load() {
 	_ = w.Ctl("show")
 }
 
+// print writes the body of the window, according to its mode.
+func (w *window) print(buf *bytes.Buffer) error {
+	var err error
+	switch w.mode {
+	case modeNewItem:
+		err = printNewItemForProject(buf, w.projectID)
+	case modeNewProject:
+		// Leave buffer empty.
+	case modeItem:
+		err = printItemByID(buf, w.itemID)
+	case modeProject:
+		err = printProjectByID(buf, w.projectID)
+	case modeSearch:
+		err = printSearch(buf, w.expr)
+	case modeAllProjects:
+		err = printAllProjects(buf)
+	case modeCalendar:
+		err = printCalendar(buf)
+	case modeArchivedProjects:
+		err = printArchivedProjects(buf)
+	case modeCompleted:
+		err = printCompleted(buf)
+	case modeReminders:
+		err = printReminders(buf)
+	case modeFilters:
+		err = printFilters(buf)
+	case modeToday:
+		err = printSearch(buf, "today")
+	case modeUpcoming:
+		err = printUpcoming(buf)
+	case modeOverdue:
+		err = printSearch(buf, "overdue")
+	}
+	return err
+}
+
 func (w *window) sort() {
 	if err := w.Addr("0/^[0-9]/,"); err != nil {
 		w.Err("nothing to sort")
@@ -296,7 +445,7 @@ func (w *window) sort() {
 	} else {
 		less = func(a, b string) bool { return skipField(a) < skipField(b) }
 	}
-	if err := w.Sort(less); err != nil {
+	if err := w.sortLines(less); err != nil {
 		w.Errf("Could not sort: %v", err.Error())
 	}
 	_ = w.Addr("0")
@@ -304,6 +453,73 @@ func (w *window) sort() {
 	_ = w.Ctl("show")
 }
 
+// sortLines is like acme.Win.Sort, except that the lines are sorted with sortSiblings, so that a Put after a Sort
+// doesn't move items across sections or parents.
+func (w *window) sortLines(less func(string, string) bool) error {
+	q0, q1, err := w.ReadAddr()
+	if err != nil {
+		return err
+	}
+	data, err := w.ReadAll("xdata")
+	if err != nil {
+		return err
+	}
+	suffix := ""
+	lines := strings.Split(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		suffix = "\n"
+		lines = lines[:len(lines)-1]
+	}
+	if err := w.Addr("#%d,#%d", q0, q1); err != nil {
+		return err
+	}
+	_, err = w.Write("data", []byte(strings.Join(sortSiblings(lines, less), "\n")+suffix))
+	return err
+}
+
+// sortSiblings sorts the lines of a project or all-projects window without changing the tree they describe: lines
+// only move among their siblings, within the same section, and sub-tasks (sub-projects) move along with their
+// parent. Lines that don't start with an id, such as section headers, stay where they are.
+func sortSiblings(lines []string, less func(string, string) bool) []string {
+	var sorted, run []string
+	for _, line := range lines {
+		if len(line) > 0 && '0' <= line[0] && line[0] <= '9' {
+			run = append(run, line)
+			continue
+		}
+		sorted = append(sorted, sortTree(run, less)...)
+		sorted = append(sorted, line)
+		run = nil
+	}
+	return append(sorted, sortTree(run, less)...)
+}
+
+// sortTree sorts the blocks made of a line and the more indented lines below it, and recursively the lines in each
+// block. See lineIndentation.
+func sortTree(lines []string, less func(string, string) bool) []string {
+	if len(lines) == 0 {
+		return nil
+	}
+	base, _ := lineIndentation(lines[0])
+	var blocks [][]string
+	for _, line := range lines {
+		if indent, _ := lineIndentation(line); len(blocks) == 0 || indent <= base {
+			blocks = append(blocks, []string{line})
+		} else {
+			blocks[len(blocks)-1] = append(blocks[len(blocks)-1], line)
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return less(blocks[i][0], blocks[j][0])
+	})
+	var sorted []string
+	for _, block := range blocks {
+		sorted = append(sorted, block[0])
+		sorted = append(sorted, sortTree(block[1:], less)...)
+	}
+	return sorted
+}
+
 func lineNumber(s string) int {
 	n := 0
 	for j := 0; j < len(s) && '0' <= s[j] && s[j] <= '9'; j++ {
@@ -323,6 +539,136 @@ func skipField(s string) string {
 	return s[i:]
 }
 
+// indentedItem is an item on a line of a project window, see lineIndentation.
+type indentedItem struct {
+	indent int
+	id     todoist.ID
+	item   *todoist.Item // Nil for items being added.
+}
+
+// commandIDs parses the ids given as arguments to a command, e.g., "1234 5678" for "Uncomplete 1234 5678". Without
+// arguments, the ids are taken from the beginning of the selected lines.
+func commandIDs(w *window, args string) ([]int64, error) {
+	var ids []int64
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		for _, line := range strings.Split(w.Selection(), "\n") {
+			if line := strings.Fields(line); len(line) > 0 {
+				if id, err := strconv.ParseInt(line[0], 10, 64); err == nil {
+					ids = append(ids, id)
+				}
+			}
+		}
+		if len(ids) == 0 {
+			return nil, errors.New("no ids given or selected")
+		}
+		return ids, nil
+	}
+	for _, field := range fields {
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// indentedProject is a project on a line of the all-projects window, see lineIndentation.
+type indentedProject struct {
+	indent int
+	id     int64
+}
+
+// lineSection is the section of the items following a section header in a project window.
+type lineSection struct {
+	id      todoist.ID
+	section *todoist.Section // Nil for sections being added.
+}
+
+// putSectionHeader queues the commands to add, rename, move, or reorder a section, according to the fields of a
+// header line in a project window, e.g., "Section: 1234 Name", or "Section: 0 Name" to add a section.
+func putSectionHeader(projectID int64, fields []string, order int, reorder *todoist.ReorderCommand) (*lineSection, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("missing section id")
+	}
+	id, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.Join(fields[1:], " ")
+	if id == 0 {
+		if name == "" {
+			return nil, errors.New("missing section name")
+		}
+		tempID := client.QueueSectionAdd(
+			todoist.NewSectionPatch(0).WithProjectID(todoist.NewID(projectID)).WithName(name).WithSectionOrder(order),
+		)
+		return &lineSection{id: todoist.NewTemporaryID(tempID)}, nil
+	}
+	section, ok := client.SectionByID(id)
+	if !ok {
+		return nil, fmt.Errorf("section %d: %w", id, errNotFound)
+	}
+	if name != "" && name != section.Name {
+		client.QueueSectionUpdate(todoist.NewSectionPatch(id).WithName(name))
+	}
+	if section.ProjectID != projectID {
+		client.QueueSectionMove(todoist.NewID(id), todoist.NewID(projectID))
+	}
+	if section.SectionOrder != order {
+		reorder.Add(id, order)
+	}
+	return &lineSection{id: todoist.NewID(id), section: section}, nil
+}
+
+// lineIndentation returns the indentation of the content of a line in a project window, and the content itself.
+// It works for lines in the all-projects window too, where the content is the project name.
+// The content follows the child order, e.g., "(42) ", or the id, for lines adding items, e.g., "0 new item". Tabs
+// count as one level of indentation, see printItemLine.
+func lineIndentation(line string) (indent int, content string) {
+	content = strings.TrimLeft(strings.TrimLeft(line, " \t"), "0123456789")
+	if id := strings.Fields(line); len(id) > 0 && id[0] != "0" {
+		if i := orderNumberEnd(line); i >= 0 {
+			content = line[i:]
+		}
+	}
+	if len(content) > 0 && (content[0] == ' ' || content[0] == '\t') {
+		content = content[1:]
+	}
+	for _, r := range content {
+		switch r {
+		case ' ':
+			indent++
+		case '\t':
+			indent += len(indentation)
+		default:
+			return indent, strings.TrimSpace(content)
+		}
+	}
+	return indent, ""
+}
+
+// orderNumberEnd returns the index just past the first field of the form (42) in the line, or -1.
+func orderNumberEnd(line string) int {
+	for start := strings.Index(line, "("); start >= 0; {
+		end := strings.Index(line[start:], ")")
+		if end < 0 {
+			return -1
+		}
+		end += start + 1
+		if isOrderNumber(line[start:end]) && (start == 0 || line[start-1] == ' ' || line[start-1] == '\t') {
+			return end
+		}
+		next := strings.Index(line[start+1:], "(")
+		if next < 0 {
+			return -1
+		}
+		start += next + 1
+	}
+	return -1
+}
+
 func isOrderNumber(s string) bool {
 	l := len(s)
 	if l == 0 {
@@ -341,6 +687,281 @@ func isOrderNumber(s string) bool {
 	return i+1 == l
 }
 
+// filterLine is a line of the filters window, see putFilters.
+type filterLine struct {
+	filter *todoist.Filter // Nil for filters being added.
+	name   string
+	query  string
+}
+
+// putFilters queues the commands to add, update, and reorder filters according to the lines of the filters window,
+// in the form "id<tab>name<tab>query", where id 0 adds a filter. Use Zap to delete a filter. Nothing is queued
+// unless all lines can be parsed.
+func (w *window) putFilters() error {
+	data, err := w.ReadAll("body")
+	if err != nil {
+		return err
+	}
+	var lines []filterLine
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			return fmt.Errorf("want id, name, and query separated by tabs: %q", line)
+		}
+		id, err := strconv.ParseInt(strings.TrimSpace(fields[0]), 10, 64)
+		if err != nil {
+			return err
+		}
+		l := filterLine{name: strings.TrimSpace(fields[1]), query: strings.TrimSpace(fields[2])}
+		if id != 0 {
+			filter, ok := client.FilterByID(id)
+			if !ok {
+				return fmt.Errorf("filter %d: %w", id, errNotFound)
+			}
+			l.filter = filter
+		}
+		lines = append(lines, l)
+	}
+	var reorder todoist.ReorderCommand
+	for i, l := range lines {
+		order := i + 1
+		if l.filter == nil {
+			client.QueueFilterAdd(todoist.NewFilterPatch(0).WithName(l.name).WithQuery(l.query).WithItemOrder(order))
+			continue
+		}
+		patch := todoist.NewFilterPatch(l.filter.ID)
+		if l.name != l.filter.Name {
+			patch.WithName(l.name)
+		}
+		if l.query != l.filter.Query {
+			patch.WithQuery(l.query)
+		}
+		if l.name != l.filter.Name || l.query != l.filter.Query {
+			client.QueueFilterUpdate(patch)
+		}
+		if l.filter.ItemOrder != order {
+			reorder.Add(l.filter.ID, order)
+		}
+	}
+	if !reorder.Empty() {
+		client.QueueFilterReorder(&reorder)
+	}
+	return client.Push()
+}
+
+// queueLabels returns the ids of the labels with the given names, queueing the commands to add the missing ones.
+func queueLabels(names []string) []todoist.ID {
+	var labels []todoist.ID
+	for _, name := range names {
+		label := client.LabelByName(name)
+		if label != nil {
+			labels = append(labels, todoist.NewID(label.ID))
+		} else {
+			tid := client.QueueLabelAdd(todoist.NewLabelPatch(0).WithName(name))
+			labels = append(labels, todoist.NewTemporaryID(tid))
+		}
+	}
+	return labels
+}
+
+// itemLine is the edited state of an item on a line of a search, calendar, or agenda window, see printItemLineDue.
+type itemLine struct {
+	item     *todoist.Item
+	priority int
+	labels   []string // Nil if unchanged.
+	project  *todoist.Project
+	due      string // Empty if unchanged.
+	content  string
+}
+
+// relativeDuration matches the due column as printed by printItemLineDue, e.g., "1d3h" or "-45m".
+var relativeDuration = regexp.MustCompile(`^(-?[0-9]+d)?(-?[0-9]+h)?(-?[0-9]+m)?$`)
+
+// parseItemLine parses a line of a search, calendar, or agenda window, of the form
+//
+//	id priority [#project] [@label...] [due] (order) content
+//
+// where project and due, which may contain spaces, are only there to change them. The due column, if it isn't a
+// relative time as printed, is a date, as in item windows, or a due string. Backslashes escape spaces in project
+// names, e.g., "#Home\ improvement". It returns nil for lines that don't start with an item id, such as day headers.
+func parseItemLine(line string) (*itemLine, error) {
+	end := orderNumberEnd(line)
+	fields := strings.Fields(line)
+	if len(fields) == 0 || end < 0 {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	item, ok := client.ItemByID(id)
+	if !ok {
+		return nil, fmt.Errorf("item %d: %w", id, errNotFound)
+	}
+	fields = escapedFields(line[:strings.LastIndex(line[:end], "(")])
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("item %d: missing priority", id)
+	}
+	l := &itemLine{item: item}
+	if l.priority, err = todoist.ParsePriority(fields[1]); err != nil {
+		return nil, fmt.Errorf("item %d: %w", id, err)
+	}
+	var due []string
+	for _, field := range fields[2:] {
+		switch {
+		case len(due) == 0 && len(field) > 1 && field[0] == '#':
+			name := field[1:]
+			for _, p := range client.SearchProjects().WithName(name).WithIsArchived(0).WithIsDeleted(0).Results() {
+				if strings.EqualFold(p.Name, name) {
+					l.project = p
+				}
+			}
+			if l.project == nil {
+				return nil, fmt.Errorf("item %d: project %q: %w", id, name, errNotFound)
+			}
+		case len(due) == 0 && len(field) > 1 && field[0] == '@':
+			l.labels = append(l.labels, field[1:])
+		default:
+			due = append(due, field)
+		}
+	}
+	sort.Strings(l.labels)
+	if labels, err := getLabelNames(item.Labels); err != nil {
+		return nil, err
+	} else if strings.Join(labels, " ") == strings.Join(l.labels, " ") {
+		l.labels = nil
+	} else if l.labels == nil {
+		// All labels removed.
+		l.labels = []string{}
+	}
+	if s := strings.Join(due, " "); !relativeDuration.MatchString(s) {
+		l.due = s
+	}
+	_, l.content = lineIndentation(line)
+	return l, nil
+}
+
+// escapedFields is like strings.Fields, except that spaces preceded by a backslash don't separate fields.
+func escapedFields(s string) []string {
+	var fields []string
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && s[i+1] == ' ':
+			b.WriteByte(' ')
+			i++
+		case c == ' ' || c == '\t':
+			if b.Len() > 0 {
+				fields = append(fields, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if b.Len() > 0 {
+		fields = append(fields, b.String())
+	}
+	return fields
+}
+
+// printedIDs returns the ids at the beginning of the lines of a window's body, in order, without repetitions.
+func printedIDs(body string) []int64 {
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, line := range strings.Split(body, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			if id, err := strconv.ParseInt(fields[0], 10, 64); err == nil && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// putItemLines applies the edits to a search, calendar, or agenda window, see queueItemLines.
+func (w *window) putItemLines() error {
+	data, err := w.ReadAll("body")
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	listed := w.listed
+	w.mu.Unlock()
+	return queueItemLines(string(data), listed)
+}
+
+// queueItemLines queues and pushes the commands to update, move, and complete items according to the lines of a
+// search, calendar, or agenda window, see parseItemLine. The listed items, i.e., those printed when the window was
+// loaded, whose lines have been deleted are completed; other items are never completed, even if they would be
+// listed were the window loaded now. Recurring items are listed once for each occurrence in the calendar window:
+// only the first line of each item counts. Nothing is queued unless all lines can be parsed.
+func queueItemLines(body string, listed []int64) error {
+	var lines []*itemLine
+	seen := make(map[int64]bool)
+	for _, line := range strings.Split(body, "\n") {
+		l, err := parseItemLine(line)
+		if err != nil {
+			return err
+		}
+		if l == nil || seen[l.item.ID] {
+			continue
+		}
+		seen[l.item.ID] = true
+		lines = append(lines, l)
+	}
+	var closed []int64
+	for _, id := range listed {
+		if item, ok := client.ItemByID(id); ok && item.Checked == 0 && !seen[id] {
+			closed = append(closed, id)
+		}
+	}
+	for _, l := range lines {
+		item := l.item
+		patch := todoist.NewItemPatch(item.ID)
+		changed := false
+		if l.priority != item.Priority {
+			patch.WithPriority(l.priority)
+			changed = true
+		}
+		if l.labels != nil {
+			patch.WithLabels(queueLabels(l.labels)...)
+			changed = true
+		}
+		if l.due != "" {
+			if due, err := todoist.ParseDue(l.due); err == nil {
+				patch.WithDue(due)
+			} else {
+				patch.WithDueString(l.due, dueLang)
+			}
+			changed = true
+		}
+		// Hard to imagine one intends to make the content empty.
+		if l.content != "" && l.content != item.Content {
+			patch.WithContent(l.content)
+			changed = true
+		}
+		if changed {
+			client.QueueItemUpdate(patch)
+		}
+		if l.project != nil && l.project.ID != item.ProjectID {
+			client.QueueItemMove(todoist.NewID(item.ID), todoist.NewID(l.project.ID))
+		}
+	}
+	for _, id := range closed {
+		client.QueueItemClose(id)
+	}
+	err := client.Push()
+	if len(closed) > 0 {
+		invalidateCompleted()
+	}
+	return err
+}
+
 // Execute is triggered by button-2 click in acme.
 func (w *window) Execute(cmd string) bool {
 	if strings.HasPrefix(cmd, "Search ") {
@@ -369,6 +990,15 @@ func (w *window) Execute(cmd string) bool {
 		if err != nil {
 			return false
 		}
+		if filter, ok := client.FilterByID(id); ok && w.mode == modeFilters {
+			client.QueueFilterDelete(id)
+			if err := client.Push(); err != nil {
+				w.Errf("Could not delete filter %q: %v", filter.Name, err)
+			} else {
+				onFiltersPut()
+			}
+			return true
+		}
 		if note, ok := client.NoteByID(id); ok {
 			client.QueueNoteDelete(id)
 			if err := client.Push(); err != nil {
@@ -396,14 +1026,72 @@ func (w *window) Execute(cmd string) bool {
 		}
 		return false
 	}
+	if strings.HasPrefix(cmd, "Unarchive ") {
+		id, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(cmd, "Unarchive ")), 10, 64)
+		if err != nil {
+			return false
+		}
+		client.QueueProjectUnarchive(id)
+		if err := client.Push(); err != nil {
+			w.Errf("Could not unarchive project %d: %v", id, err)
+		} else {
+			onDataChanged()
+		}
+		return true
+	}
+	if cmd == "Uncomplete" || strings.HasPrefix(cmd, "Uncomplete ") {
+		ids, err := commandIDs(w, strings.TrimPrefix(cmd, "Uncomplete"))
+		if err != nil {
+			w.Errf("Uncomplete: %v", err)
+			return true
+		}
+		for _, id := range ids {
+			client.QueueItemUncomplete(id)
+		}
+		if err := client.Push(); err != nil {
+			w.Errf("Could not uncomplete items: %v", err)
+		}
+		invalidateCompleted()
+		onDataChanged()
+		return true
+	}
+	if cmd == "Postpone" || strings.HasPrefix(cmd, "Postpone ") {
+		w.reschedule("Postpone", strings.TrimPrefix(cmd, "Postpone"), 1)
+		return true
+	}
+	if cmd == "Today" || strings.HasPrefix(cmd, "Today ") {
+		w.reschedule("Today", strings.TrimPrefix(cmd, "Today"), 0)
+		return true
+	}
 	switch cmd {
 	case "Projects":
 		newAllProjectsWindow()
 		return true
+	case "Archived":
+		newArchivedProjectsWindow()
+		return true
+	case "Completed":
+		newCompletedWindow()
+		return true
+	case "Filters":
+		newFiltersWindow()
+		return true
 	case "Calendar":
 		newCalendarWindow()
 		return true
+	case "Agenda":
+		newAgendaWindow(modeToday)
+		return true
+	case "Upcoming":
+		newAgendaWindow(modeUpcoming)
+		return true
+	case "Overdue":
+		newAgendaWindow(modeOverdue)
+		return true
 	case "Get":
+		if w.mode == modeCompleted {
+			invalidateCompleted()
+		}
 		w.load()
 		return true
 	case "Put", "PutDel":
@@ -414,7 +1102,7 @@ func (w *window) Execute(cmd string) bool {
 				if err != nil {
 					return "", err
 				}
-				pp := todoist.NewProjectPatch(0).WithColor(31).WithName(strings.TrimSpace(string(name))).WithChildOrder(1)
+				pp := todoist.NewProjectPatch(0).WithName(strings.TrimSpace(string(name))).WithChildOrder(1)
 				tempID := client.QueueProjectAdd(pp)
 				return tempID, client.Push()
 			}()
@@ -471,6 +1159,8 @@ func (w *window) Execute(cmd string) bool {
 				if err != nil {
 					return err
 				}
+				// Analogous to the parents of items in project windows.
+				var parents []indentedProject
 				lines := strings.Split(string(data), "\n")
 				for i, line := range lines {
 					fields := strings.Fields(line)
@@ -490,15 +1180,22 @@ func (w *window) Execute(cmd string) bool {
 					if p.ChildOrder != i {
 						reorder.Add(id, i)
 					}
-					fields = fields[1:]            // Skip project ID.
-					for isOrderNumber(fields[0]) { // Skip fields of the form (42).
-						fields = fields[1:]
+					indent, name := lineIndentation(line)
+					if len(name) > 0 && p.Name != name {
+						client.QueueProjectUpdate(todoist.NewProjectPatch(id).WithName(name))
 					}
-					if name := strings.TrimSpace(strings.Join(fields, " ")); len(name) > 0 {
-						if p.Name != name {
-							client.QueueProjectUpdate(todoist.NewProjectPatch(id).WithName(name))
-						}
+					for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+						parents = parents[:len(parents)-1]
+					}
+					switch {
+					case p.InboxProject:
+						// The inbox can't be moved.
+					case len(parents) > 0 && p.ParentID != parents[len(parents)-1].id:
+						client.QueueProjectMove(todoist.NewID(id), todoist.NewID(parents[len(parents)-1].id))
+					case len(parents) == 0 && p.ParentID != 0:
+						client.QueueProjectMoveToTop(todoist.NewID(id))
 					}
+					parents = append(parents, indentedProject{indent: indent, id: id})
 				}
 				if !reorder.Empty() {
 					client.QueueProjectReorder(&reorder)
@@ -514,46 +1211,98 @@ func (w *window) Execute(cmd string) bool {
 				}
 				onAllProjectsPut()
 			}
+		} else if w.mode == modeFilters {
+			if err := w.putFilters(); err != nil {
+				w.Errf("Could not update filters: %v", err)
+			} else {
+				_ = w.Ctl("clean")
+				if del {
+					_ = w.Del(true)
+				}
+				onFiltersPut()
+			}
 		} else if w.mode == modeProject {
 			err := func() error {
 				projectID := todoist.NewID(w.projectID)
-				var reorder todoist.ReorderCommand
+				var reorder, sectionReorder todoist.ReorderCommand
 				data, err := w.ReadAll("body")
 				if err != nil {
 					return err
 				}
+				// The items on the preceding lines that are less indented than the current one, the last being
+				// the current line's parent.
+				var parents []indentedItem
+				// The section whose header precedes the current line, if any.
+				var section *lineSection
+				var sectionOrder int
+				skipSection := false
 				lines := strings.Split(string(data), "\n")
 				for i, line := range lines {
 					fields := strings.Fields(line)
 					if len(fields) == 0 || fields[0] == "Project:" {
 						continue
 					}
+					if fields[0] == "Section:" {
+						parents = nil
+						sectionOrder++
+						section, err = putSectionHeader(w.projectID, fields[1:], sectionOrder, &sectionReorder)
+						if skipSection = err != nil; skipSection {
+							log.WithField("line", line).WithError(err).Warning("Ignoring section")
+						}
+						continue
+					}
+					if skipSection {
+						continue
+					}
 					id, err := strconv.ParseInt(fields[0], 10, 64)
 					if err != nil {
 						log.WithField("line", line).Warning("Ignoring line that does not start with a number")
 						continue
 					}
+					indent, content := lineIndentation(line)
+					for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+						parents = parents[:len(parents)-1]
+					}
+					var parent *indentedItem
+					if len(parents) > 0 {
+						parent = &parents[len(parents)-1]
+					}
 					if id == 0 {
-						client.QueueItemAdd(
-							todoist.NewItemPatch(0).WithProjectID(w.projectID).WithChildOrder(i).WithContent(strings.Join(fields[1:], " ")),
-						)
+						item := todoist.NewItemPatch(0).WithProjectID(w.projectID).WithChildOrder(i).WithContent(content)
+						if parent != nil {
+							item.WithParentID(parent.id)
+						}
+						if section != nil {
+							item.WithSectionID(section.id)
+						}
+						tempID := client.QueueItemAdd(item)
+						parents = append(parents, indentedItem{indent: indent, id: todoist.NewTemporaryID(tempID)})
 					} else {
 						item, ok := client.ItemByID(id)
 						if !ok {
 							log.WithField("line", line).Warning("Ignoring line that refers to an unknown item")
 							continue
 						}
-						if item.ProjectID != w.projectID {
+						switch {
+						case parent != nil && (parent.item == nil || item.ParentID != parent.item.ID):
+							client.QueueItemMoveToParent(todoist.NewID(item.ID), parent.id)
+						case parent == nil && section != nil && (section.section == nil || item.SectionID != section.section.ID || item.ParentID != 0):
+							client.QueueItemMoveToSection(todoist.NewID(item.ID), section.id)
+						case parent == nil && section == nil && (item.ParentID != 0 || item.SectionID != 0 || item.ProjectID != w.projectID):
 							client.QueueItemMove(todoist.NewID(item.ID), projectID)
 						}
 						if item.ChildOrder != i {
 							reorder.Add(id, i)
 						}
+						parents = append(parents, indentedItem{indent: indent, id: todoist.NewID(item.ID), item: item})
 					}
 				}
 				if !reorder.Empty() {
 					client.QueueItemReorder(&reorder)
 				}
+				if !sectionReorder.Empty() {
+					client.QueueSectionReorder(&sectionReorder)
+				}
 				return client.Push()
 			}()
 			if err != nil {
@@ -584,10 +1333,39 @@ func (w *window) Execute(cmd string) bool {
 				}
 				onItemPut(w.itemID, w.projectID)
 			}
+		} else if w.mode == modeSearch || w.mode == modeCalendar || w.mode == modeToday || w.mode == modeUpcoming || w.mode == modeOverdue {
+			if err := w.putItemLines(); err != nil {
+				w.Errf("Could not update items: %v", err)
+			} else {
+				_ = w.Ctl("clean")
+				if del {
+					_ = w.Del(true)
+				}
+				onDataChanged()
+			}
 		} else {
 			w.Errf("Put forbidden for this window mode: %v", w.mode)
 		}
 		return true
+	case "Retry":
+		client.RetryCommands()
+		if err := client.Push(); err != nil {
+			w.Errf("Could not push retried commands: %v", err)
+		}
+		onDataChanged()
+		return true
+	case "Drop":
+		client.DropCommands()
+		onDataChanged()
+		return true
+	case "Resync":
+		if diff, err := client.Resync(); err != nil {
+			w.Errf("Could not resync: %v", err)
+		} else {
+			w.Errf("Resync: %v", diff)
+			onDataChanged()
+		}
+		return true
 	case "Del":
 		_ = w.Del(false)
 		return true
@@ -608,12 +1386,24 @@ func (w *window) Execute(cmd string) bool {
 			w.Errf("Window mode does not allow sorting: %v", w.mode)
 		}
 		return true
+	case "Preview":
+		if w.mode == modeItem || w.mode == modeNewItem {
+			w.previewDue()
+		} else {
+			w.Errf("Preview only works in item windows, mode is %v", w.mode)
+		}
+		return true
 	case "Complete":
 		if w.mode == modeItem {
 			if item, ok := client.ItemByID(w.itemID); ok {
 				client.QueueItemClose(w.itemID)
-				if err := client.Push(); err != nil {
+				err := client.Push()
+				invalidateCompleted()
+				if err != nil {
 					w.Errf("Could not complete item: %v", err)
+				} else if item.Due != nil && item.Due.IsRecurring {
+					// The item stays, due at the next occurrence.
+					onItemPut(item.ID, item.ProjectID)
 				} else {
 					// Same reaction to complete and delete.
 					onItemZapped(item.ID, item.ProjectID)
@@ -630,6 +1420,81 @@ func (w *window) Execute(cmd string) bool {
 	}
 }
 
+// reschedule moves the items with the given ids, or selected, to the given number of days after today, or after
+// their due date if that's later, keeping the time of the day. Recurring items are left alone, as rescheduling them
+// would lose the recurrence.
+func (w *window) reschedule(cmd string, args string, days int) {
+	ids, err := commandIDs(w, args)
+	if err != nil {
+		w.Errf("%s: %v", cmd, err)
+		return
+	}
+	loc := client.Location()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	queued := 0
+	for _, id := range ids {
+		item, ok := client.ItemByID(id)
+		if !ok {
+			w.Errf("%s: item not found: %d", cmd, id)
+			continue
+		}
+		if item.Due != nil && item.Due.IsRecurring {
+			w.Errf("%s: skipping recurring item %d, due %q", cmd, id, item.Due.String)
+			continue
+		}
+		due := todoist.DueOn(today)
+		day := today
+		if item.Due != nil && item.Due.Date != "" {
+			due = *item.Due
+			if d := due.Day(loc); days != 0 && d.After(day) {
+				day = d
+			}
+		}
+		client.QueueItemUpdate(todoist.NewItemPatch(id).WithDue(due.Reschedule(day.AddDate(0, 0, days), loc)))
+		queued++
+	}
+	if queued == 0 {
+		return
+	}
+	if err := client.Push(); err != nil {
+		w.Errf("%s: could not reschedule items: %v", cmd, err)
+	}
+	onDataChanged()
+}
+
+// The language of due strings typed in item windows.
+const dueLang = "en"
+
+// previewDue shows the due date that Put would set, as far as the client can tell, see todoist.ParseDueString.
+func (w *window) previewDue() {
+	data, err := w.ReadAll("body")
+	if err != nil {
+		w.Errf("Could not read window: %v", err)
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "Due:") {
+			continue
+		}
+		s := strings.TrimSpace(line[len("Due:"):])
+		if s == "" {
+			w.Errf("Preview: no due date")
+			return
+		}
+		due, err := todoist.ParseDue(s)
+		if err != nil {
+			if due, err = todoist.ParseDueString(s, time.Now().In(client.Location())); err != nil {
+				w.Errf("Preview: %v (Todoist may still understand it)", err)
+				return
+			}
+		}
+		w.Errf("Preview: %s: %s, recurring: %v", s, dueDateFormat(&due), due.IsRecurring)
+		return
+	}
+	w.Errf("Preview: no Due: line")
+}
+
 // Reads up the body and parses it to update properties in the passed item object.
 func (w *window) populateItem(item *todoist.ItemPatch, note *todoist.NotePatch) error {
 	data, err := w.ReadAll("body")
@@ -645,26 +1510,32 @@ func (w *window) populateItem(item *todoist.ItemPatch, note *todoist.NotePatch)
 				item.WithContent(c)
 			}
 		} else if strings.HasPrefix(line, "Labels:") {
-			var labels []todoist.ID
-			for _, name := range strings.Fields(line[len("Labels:"):]) {
-				label := client.LabelByName(name)
-				if label != nil {
-					labels = append(labels, todoist.NewID(label.ID))
-				} else {
-					tid := client.QueueLabelAdd(todoist.NewLabelPatch(0).WithName(name))
-					labels = append(labels, todoist.NewTemporaryID(tid))
+			item.WithLabels(queueLabels(strings.Fields(line[len("Labels:"):]))...)
+		} else if strings.HasPrefix(line, "Priority:") {
+			if name := strings.TrimSpace(line[len("Priority:"):]); name != "" {
+				priority, err := todoist.ParsePriority(name)
+				if err != nil {
+					return err
 				}
+				item.WithPriority(priority)
 			}
-			item.WithLabels(labels...)
 		} else if strings.HasPrefix(line, "Note:") {
 			if content := strings.TrimSpace(line[len("Note:"):]); content != "" {
 				note.WithContent(content)
 			}
 		} else if strings.HasPrefix(line, "Due:") {
-			// Format should be "2019-08-03" or "2019-08-03T15:30:00Z" (due day, due day and time,
-			// respectively.)
-			if date := strings.TrimSpace(line[len("Due:"):]); date != "" {
-				item.WithDue(date)
+			// Either a date such as "2019-08-03", "2019-08-03T15:30:00", or "2019-08-03T13:30:00Z" (all-day,
+			// floating, and fixed-zone, respectively), or a due string for Todoist to parse, such as "every
+			// monday 9am". An unchanged due date is not sent again, so as to keep its timezone and recurrence.
+			if s := strings.TrimSpace(line[len("Due:"):]); s != "" {
+				if existing, ok := client.ItemByID(w.itemID); ok && existing.Due != nil && (existing.Due.String == s || existing.Due.Date == s) {
+					continue
+				}
+				if due, err := todoist.ParseDue(s); err == nil {
+					item.WithDue(due)
+				} else {
+					item.WithDueString(s, dueLang)
+				}
 			}
 		}
 	}
@@ -689,12 +1560,23 @@ func onAllProjectsPut() {
 	}
 }
 
+func onFiltersPut() {
+	all.Lock()
+	defer all.Unlock()
+	for _, w := range all.m {
+		switch w.mode {
+		case modeFilters, modeSearch:
+			w.load()
+		}
+	}
+}
+
 func onItemPut(itemID, projectID int64) {
 	all.Lock()
 	defer all.Unlock()
 	for _, w := range all.m {
 		switch w.mode {
-		case modeSearch, modeCalendar:
+		case modeSearch, modeCalendar, modeToday, modeUpcoming, modeOverdue:
 			w.load()
 		case modeProject:
 			if w.projectID == projectID {
@@ -731,7 +1613,7 @@ func onProjectZapped(projectID int64) {
 	defer all.Unlock()
 	for _, w := range all.m {
 		switch w.mode {
-		case modeAllProjects, modeSearch, modeCalendar:
+		case modeAllProjects, modeArchivedProjects, modeSearch, modeCalendar, modeToday, modeUpcoming, modeOverdue:
 			w.load()
 		case modeItem, modeNewItem, modeProject:
 			if w.projectID == projectID {
@@ -745,7 +1627,8 @@ func onItemZapped(itemID, projectID int64) {
 	all.Lock()
 	defer all.Unlock()
 	for _, w := range all.m {
-		if w.mode == modeSearch || w.mode == modeCalendar {
+		switch w.mode {
+		case modeSearch, modeCalendar, modeToday, modeUpcoming, modeOverdue:
 			w.load()
 		}
 		if w.mode == modeProject && w.projectID == projectID {
@@ -757,6 +1640,17 @@ func onItemZapped(itemID, projectID int64) {
 	}
 }
 
+// onDataChanged reloads all windows, for changes that can affect any entity.
+func onDataChanged() {
+	all.Lock()
+	defer all.Unlock()
+	for _, w := range all.m {
+		if w.mode != modeNewItem && w.mode != modeNewProject {
+			w.load()
+		}
+	}
+}
+
 func onNoteZapped(itemID int64) {
 	all.Lock()
 	defer all.Unlock()
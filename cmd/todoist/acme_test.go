@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient sets the client to one pulling the given items, and returns the commands it pushes.
+func newTestClient(t *testing.T, items string) (pushed func() []map[string]interface{}, closeServer func()) {
+	var mu sync.Mutex
+	var commands []map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if b := r.PostForm.Get("commands"); b != "" {
+			var batch []map[string]interface{}
+			if err := json.Unmarshal([]byte(b), &batch); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			status := make(map[string]string)
+			for _, cmd := range batch {
+				status[cmd["uuid"].(string)] = "ok"
+			}
+			mu.Lock()
+			commands = append(commands, batch...)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"sync_status": status})
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{
+			"sync_token": "t",
+			"items": %s,
+			"projects": [{"id": 10, "name": "Inbox"}],
+			"user": {"id": 7, "tz_info": {"timezone": "UTC"}}
+		}`, items)
+	}))
+	var err error
+	client, err = todoist.NewClient("token", todoist.WithEndpoint(ts.URL), todoist.WithStore(todoist.NewMemoryStore()))
+	require.Nil(t, err)
+	require.Nil(t, client.Pull())
+	return func() []map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return commands
+	}, ts.Close
+}
+
+func TestQueueItemLinesOnlyCompletesListedItems(t *testing.T) {
+	pushed, closeServer := newTestClient(t, `[
+		{"id": 1, "project_id": 10, "content": "kept", "priority": 1},
+		{"id": 2, "project_id": 10, "content": "deleted line", "priority": 1},
+		{"id": 3, "project_id": 10, "content": "not listed when loaded", "priority": 1}
+	]`)
+	defer closeServer()
+	item, ok := client.ItemByID(1)
+	require.True(t, ok)
+	var body bytes.Buffer
+	require.Nil(t, printItemLine(&body, item, 0))
+
+	require.Nil(t, queueItemLines(body.String(), []int64{1, 2}))
+	commands := pushed()
+	require.Len(t, commands, 1)
+	assert.Equal(t, "item_close", commands[0]["type"])
+	assert.Equal(t, map[string]interface{}{"id": float64(2)}, commands[0]["args"])
+}
+
+func TestQueueItemLinesEdits(t *testing.T) {
+	pushed, closeServer := newTestClient(t, `[
+		{"id": 1, "project_id": 10, "content": "first", "priority": 1}
+	]`)
+	defer closeServer()
+
+	require.Nil(t, queueItemLines("1\tp1\t\t\t(0) edited", []int64{1}))
+	commands := pushed()
+	require.Len(t, commands, 1)
+	assert.Equal(t, "item_update", commands[0]["type"])
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "priority": float64(4), "content": "edited"}, commands[0]["args"])
+
+	// Lines that can't be parsed make the whole Put fail, and nothing is queued.
+	assert.NotNil(t, queueItemLines("1\tp9\t\t\t(0) edited", []int64{1}))
+	assert.Len(t, pushed(), 1)
+}
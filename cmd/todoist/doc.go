@@ -1,18 +1,56 @@
 // The todoist program is an acme user interface to Todoist (https://todoist.com).
 //
-// The API token is expected at the file lib/todoist/token within the user's home directory.
+// The API token is expected at the file lib/todoist/token within the user's home directory. The local copy of the
+// data is saved in the same directory. Use the -d flag to choose another directory, e.g., for another account.
 //
 // When launched, it creates an initial window listing all projects. Operation of the window via middle-click and
 // right-click should be fairly intuitive to an acme user so I mostly won't document it.
 //
 // Be careful with the Zap command as it will delete items. With projects, it will archive rather
-// than delete. You can also delete notes by 2-button-swiping "Zap 1234" where 1234 is a note id.
+// than delete. You can also delete notes by 2-button-swiping "Zap 1234" where 1234 is a note id. Archived projects
+// are listed in the window opened by Archived, where 2-button-swiping "Unarchive 1234" restores a project.
 //
-// Example arguments to Search: All items labeled "next":  @next.  All items labeled "bug" containing the string
-// "foobar":  @bug:foobar.  All items labeled "feature" but not labeled maybe:  @feature:-@maybe.  All items in
-// projects containing the string foobar:  #foobar.
+// Changes are sent to Todoist in bulk when you Put. If Todoist rejects some of them, the error message lists them
+// and they are kept aside: execute Retry to send them again, or Drop to discard them.
 //
-// So, in summary, prepending minus negates a condition; the colon combines conditions (i.e., represents the
-// boolean AND); the @ symbol introduces a condition on the item label; the # symbol introduces a condition on
-// the item project name, while the default condition looks for substring in items.
+// In a project window, sub-tasks are indented under their parent. Change the indentation of a line and Put to make
+// the item a sub-task of the closest less indented item above it, or to move it back to the top level. Items are
+// grouped under section headers, e.g., "Section: 1234 Name". Move a line under another header and Put to move the
+// item to that section. Headers can be renamed and reordered, and a header such as "Section: 0 Name" adds a section.
+//
+// Likewise, the window listing all projects shows sub-projects indented under their parent, and Put moves projects
+// according to the indentation.
+//
+// The window opened by Completed lists the items completed in the last week, grouped by day. Select some of them
+// and execute Uncomplete, or 2-button-swipe "Uncomplete 1234", to mark them as not completed. They're fetched from
+// Todoist when the window opens and after completing items here; execute Get to fetch those completed elsewhere.
+//
+// The window opened by Filters lists the saved filters, one per line, as id, name, and query separated by tabs.
+// Right-click a filter's id or name to open a search window with its query. Edit names and queries, reorder lines,
+// or add lines with id 0, then Put. 2-button-swipe "Zap 1234" in the window to delete a filter.
+//
+// In item windows, the Due line takes a date such as 2019-08-03 or 2019-08-03T15:30:00, or anything Todoist
+// understands, such as "tomorrow 9am" or "every monday". Execute Preview to see the date it stands for before Put.
+// Completing a recurring item moves it to its next occurrence. The Calendar window lists recurring items once for
+// each occurrence in the next two weeks.
+//
+// Agenda, Upcoming, and Overdue open windows listing the items due today, in the next seven days grouped by day, and
+// in the past. Select some items and execute Postpone to move them to the day after their due date, or after today
+// if they're overdue, or Today to move them to today; 2-button-swipe "Postpone 1234" works too. Recurring items are
+// left alone, to keep their recurrence.
+//
+// Search, Calendar, and agenda windows can be edited and Put, e.g., to triage many items at once. On each line,
+// change the priority, the labels (prefixed with @), or the content; replace the relative due time with a date or a
+// due string such as "next monday"; add "#Project" after the priority to move the item to another project, escaping
+// spaces in the name with backslashes. Deleting a line completes the item.
+//
+// Reminders set on items go off while the program runs. By default, they're listed in a window that pops up; use
+// the -notify flag to print them to standard output instead, or to run a command such as "notify-send Todoist"
+// with the item content as last argument.
+//
+// If the local copy of the data seems out of date, Resync downloads everything again and reports what changed.
+//
+// The argument to Search is a query in the syntax of Todoist filters, e.g., "(today | overdue) & #Work", "@next &
+// !@maybe", "##Work & p1", or "search: foobar". See the documentation of todoist.Query for the supported
+// conditions. Text that isn't a condition looks for items containing it, so "Search foobar" works as expected.
 package main // import "github.com/nicolagi/todoist/cmd/todoist"
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"os/user"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/nicolagi/todoist"
 	log "github.com/sirupsen/logrus"
@@ -14,16 +17,25 @@ import (
 
 var client *todoist.Client
 
+// Upper bound on the duration of each remote call, so that a hung request can not freeze a window forever.
+const requestTimeout = 30 * time.Second
+
 func main() {
-	home := mustHomeDir()
-	tokenFile := path.Join(home, "lib/todoist/token")
-	wireLogFile := path.Join(home, "lib/todoist/wire.log")
+	dir := flag.String("d", path.Join(mustHomeDir(), "lib/todoist"), "directory for the API token and the state files")
+	notify := flag.String("notify", "acme", "where to send reminders: acme, stdout, none, or a command to run with the item content as last argument")
+	flag.Parse()
+	tokenFile := path.Join(*dir, "token")
+	wireLogFile := path.Join(*dir, "wire.log")
 	apiToken := mustReadTokenFile(tokenFile)
-	client = mustCreateClient(apiToken, wireLogFile)
+	client = mustCreateClient(apiToken, wireLogFile, *dir)
 
 	// Create initial window listing all projects.
 	newAllProjectsWindow()
 
+	if sink := newSink(*notify); sink != nil {
+		go runNotifier(sink)
+	}
+
 	// The program will be terminated when the last acme window owned by this process is deleted.
 	select {}
 }
@@ -55,8 +67,13 @@ func mustReadTokenFile(tokenFile string) string {
 	return strings.TrimSpace(string(b))
 }
 
-func mustCreateClient(apiToken string, wireLogFile string) *todoist.Client {
-	client, err := todoist.NewClient(apiToken, todoist.WithWireLog(wireLogFile))
+func mustCreateClient(apiToken string, wireLogFile string, stateDir string) *todoist.Client {
+	client, err := todoist.NewClient(
+		apiToken,
+		todoist.WithWireLog(wireLogFile),
+		todoist.WithStore(todoist.NewFileStore(stateDir)),
+		todoist.WithHTTPClient(&http.Client{Timeout: requestTimeout}),
+	)
 	if err != nil {
 		log.WithField("cause", err).Fatal("Could not create client")
 	}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nicolagi/todoist"
+	log "github.com/sirupsen/logrus"
+)
+
+// The reminders that went off since the program started, most recent last. Shown in the reminders window.
+var fired struct {
+	sync.Mutex
+	notifications []*todoist.Notification
+}
+
+// acmeSink records the notification and shows it in the reminders window.
+func acmeSink(n *todoist.Notification) error {
+	fired.Lock()
+	fired.notifications = append(fired.notifications, n)
+	fired.Unlock()
+	newRemindersWindow()
+	return nil
+}
+
+func printReminders(w io.Writer) error {
+	fired.Lock()
+	defer fired.Unlock()
+	for i := len(fired.notifications) - 1; i >= 0; i-- {
+		n := fired.notifications[i]
+		_, _ = fmt.Fprintf(w, "%v\t%s\t%v\n", n.Item.ID, n.Time.In(client.Location()).Format("2006-01-02 15:04"), n.Item.Content)
+	}
+	return nil
+}
+
+// newSink interprets the -notify flag: "acme", "stdout", "none", or a command to run, e.g., "notify-send Todoist".
+func newSink(spec string) todoist.Sink {
+	switch spec {
+	case "none", "":
+		return nil
+	case "acme":
+		return todoist.SinkFunc(acmeSink)
+	case "stdout":
+		return todoist.WriterSink(os.Stdout)
+	default:
+		args := strings.Fields(spec)
+		return todoist.CommandSink(args[0], args[1:]...)
+	}
+}
+
+// runNotifier keeps the data up to date and fires reminders through the sink, forever.
+func runNotifier(sink todoist.Sink) {
+	n := todoist.NewNotifier(client, sink)
+	t := time.NewTicker(time.Minute)
+	defer t.Stop()
+	for now := range t.C {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		if err := client.PullContext(ctx); err != nil {
+			log.WithField("cause", err).Warning("Could not pull before checking reminders")
+		}
+		cancel()
+		n.Check(now)
+	}
+}
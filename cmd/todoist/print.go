@@ -7,6 +7,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nicolagi/todoist"
@@ -16,9 +17,90 @@ var errNotFound = errors.New("entity not found")
 
 func printAllProjects(w io.Writer) error {
 	all := client.SearchProjects().WithIsArchived(0).WithIsDeleted(0).Results()
-	sort.Sort(projectsByChildOrder(all))
-	for _, p := range all {
-		_, _ = fmt.Fprintf(w, "%v\t(%d) %v\n", p.ID, p.ChildOrder, p.Name)
+	for _, root := range todoist.ProjectTree(all) {
+		for _, node := range root.Flatten() {
+			p := node.Project
+			_, _ = fmt.Fprintf(w, "%v\t(%d) %s%v\n", p.ID, p.ChildOrder, strings.Repeat(indentation, node.Depth), p.Name)
+		}
+	}
+	return nil
+}
+
+func printArchivedProjects(w io.Writer) error {
+	archived, err := client.ArchivedProjects()
+	if err != nil {
+		return err
+	}
+	sort.Slice(archived, func(i, j int) bool {
+		return archived[i].Name < archived[j].Name
+	})
+	for _, p := range archived {
+		_, _ = fmt.Fprintf(w, "%v\t%v\n", p.ID, p.Name)
+	}
+	return nil
+}
+
+func printFilters(w io.Writer) error {
+	filters := client.SearchFilters().Results()
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].ItemOrder < filters[j].ItemOrder
+	})
+	for _, f := range filters {
+		_, _ = fmt.Fprintf(w, "%v\t%v\t%v\n", f.ID, f.Name, f.Query)
+	}
+	return nil
+}
+
+// completedDays is how far back the completed window goes.
+const completedDays = 7
+
+// completedFetched is the start of the period for which completed items were last fetched, so that the completed
+// window is only fetched again on Get, after completing items, or the day after.
+var completedFetched struct {
+	sync.Mutex
+	since time.Time
+}
+
+// invalidateCompleted makes the completed window fetch completed items again when next loaded.
+func invalidateCompleted() {
+	completedFetched.Lock()
+	defer completedFetched.Unlock()
+	completedFetched.since = time.Time{}
+}
+
+func printCompleted(w io.Writer) error {
+	loc := client.Location()
+	now := time.Now().In(loc)
+	y, m, d := now.Date()
+	since := time.Date(y, m, d-completedDays+1, 0, 0, 0, 0, now.Location())
+	completedFetched.Lock()
+	if !completedFetched.since.Equal(since) {
+		if _, err := client.FetchCompleted(since, time.Time{}); err != nil {
+			completedFetched.Unlock()
+			return err
+		}
+		completedFetched.since = since
+	}
+	completedFetched.Unlock()
+	completed := client.SearchCompleted().WithCompletedBetween(since, now.Add(time.Minute)).Results()
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].CompletedTime().After(completed[j].CompletedTime())
+	})
+	var day string
+	for _, c := range completed {
+		t := c.CompletedTime().In(loc)
+		if d := t.Format("Mon 2006-01-02"); d != day {
+			if day != "" {
+				_, _ = fmt.Fprintln(w)
+			}
+			day = d
+			_, _ = fmt.Fprintf(w, "%s\n\n", day)
+		}
+		project, err := getProjectName(c.ProjectID)
+		if err != nil {
+			project = "?"
+		}
+		_, _ = fmt.Fprintf(w, "%v\t%s\t%s\t%v\n", c.ItemID, t.Format("15:04"), project, c.Content)
 	}
 	return nil
 }
@@ -35,24 +117,124 @@ func printProjectByID(w io.Writer, id int64) error {
 		_, _ = fmt.Fprintf(w, "Note — %d — %s — %s\n\n", note.ID, note.Posted, note.Content)
 	}
 	items := client.SearchItems().WithProjectID(id).WithChecked(0).Results()
-	sort.Sort(itemsByChildOrder(items))
-	return printItems(w, items)
+	bySection := make(map[int64][]*todoist.Item)
+	for _, item := range items {
+		bySection[item.SectionID] = append(bySection[item.SectionID], item)
+	}
+	if err := printItemTree(w, bySection[0]); err != nil {
+		return err
+	}
+	sections := client.SearchSections().WithProjectID(id).WithIsArchived(false).Results()
+	sort.Sort(sectionsBySectionOrder(sections))
+	for _, section := range sections {
+		_, _ = fmt.Fprintf(w, "\nSection: %d %s\n\n", section.ID, section.Name)
+		if err := printItemTree(w, bySection[section.ID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printItemTree prints the items indenting sub-tasks under their parent.
+func printItemTree(w io.Writer, items []*todoist.Item) error {
+	for _, root := range todoist.ItemTree(items) {
+		for _, node := range root.Flatten() {
+			if err := printItemLine(w, node.Item, node.Depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func printSearch(w io.Writer, expr string) error {
-	search := client.SearchItems().WithChecked(0)
-	for _, term := range strings.Split(expr, ":") {
-		addSearchTerm(search, strings.TrimSpace(term))
+	query, err := todoist.ParseQuery(expr)
+	if err != nil {
+		return err
 	}
-	items := search.Results()
+	items := client.SearchItems().WithChecked(0).WithQuery(query, time.Now()).Results()
 	sort.Sort(itemsByDue(items))
 	return printItems(w, items)
 }
 
+// calendarDays is how far ahead the calendar window shows the further occurrences of recurring items.
+const calendarDays = 14
+
+// occurrence is an item in the calendar, due at the given time, which is the due date of the item, or a further
+// occurrence for recurring items.
+type occurrence struct {
+	item *todoist.Item
+	at   time.Time
+}
+
 func printCalendar(w io.Writer) error {
+	for _, o := range upcomingOccurrences(time.Now().AddDate(0, 0, calendarDays)) {
+		if err := printItemLineDue(w, o.item, 0, o.at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upcomingOccurrences returns the occurrences of unchecked items with a due date, the further ones of recurring
+// items being limited to the given horizon, sorted by time.
+func upcomingOccurrences(horizon time.Time) []occurrence {
 	items := client.SearchItems().WithChecked(0).WithDue().Results()
-	sort.Sort(itemsByDue(items))
-	return printItems(w, items)
+	loc := client.Location()
+	var occurrences []occurrence
+	for _, item := range items {
+		occurrences = append(occurrences, occurrence{item: item, at: item.Due.TimeIn(loc)})
+		if !item.Due.IsRecurring {
+			continue
+		}
+		// There are at most calendarDays occurrences before the horizon, the recurrences being daily at most.
+		upcoming, err := item.Due.Occurrences(calendarDays, loc)
+		if err != nil {
+			// Only the current occurrence is known for due strings the library doesn't understand.
+			continue
+		}
+		for _, t := range upcoming {
+			if t.After(horizon) {
+				break
+			}
+			occurrences = append(occurrences, occurrence{item: item, at: t})
+		}
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		if !occurrences[i].at.Equal(occurrences[j].at) {
+			return occurrences[i].at.Before(occurrences[j].at)
+		}
+		return occurrences[i].item.ID < occurrences[j].item.ID
+	})
+	return occurrences
+}
+
+// upcomingDays is how far ahead the upcoming window goes, today included.
+const upcomingDays = 7
+
+// printUpcoming prints the items due in the next days, including the further occurrences of recurring items,
+// grouped by day.
+func printUpcoming(w io.Writer) error {
+	now := time.Now().In(client.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	horizon := today.AddDate(0, 0, upcomingDays)
+	var day string
+	for _, o := range upcomingOccurrences(horizon) {
+		if o.at.Before(today) || !o.at.Before(horizon) {
+			continue
+		}
+		if d := o.at.Format("Mon 2006-01-02"); d != day {
+			if day != "" {
+				_, _ = fmt.Fprintln(w)
+			}
+			day = d
+			_, _ = fmt.Fprintf(w, "%s\n\n", day)
+		}
+		if err := printItemLineDue(w, o.item, 0, o.at); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func relativeDurationFormat(d time.Duration) string {
@@ -78,19 +260,42 @@ func relativeDurationFormat(d time.Duration) string {
 
 func printItems(w io.Writer, items []*todoist.Item) error {
 	for _, i := range items {
-		labelNames, err := getLabelNames(i.Labels)
-		if err != nil {
-			return fmt.Errorf("print items: %d: %w", i.ID, err)
+		if err := printItemLine(w, i, 0); err != nil {
+			return err
 		}
-		dueIn := ""
-		if i.Due != nil {
-			dueIn = relativeDurationFormat(time.Until(i.Due.Time()))
-		}
-		_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t(%d) %v\n", i.ID, strings.Join(labelNames, " "), dueIn, i.ChildOrder, i.Content)
 	}
 	return nil
 }
 
+// indentation is the indentation of sub-tasks in the content column, for each level of depth.
+const indentation = "    "
+
+func printItemLine(w io.Writer, i *todoist.Item, depth int) error {
+	var due time.Time
+	if i.Due != nil {
+		due = i.Due.TimeIn(client.Location())
+	}
+	return printItemLineDue(w, i, depth, due)
+}
+
+// printItemLineDue is like printItemLine, for the item due at the given time, unless zero.
+func printItemLineDue(w io.Writer, i *todoist.Item, depth int, due time.Time) error {
+	labelNames, err := getLabelNames(i.Labels)
+	if err != nil {
+		return fmt.Errorf("print items: %d: %w", i.ID, err)
+	}
+	// Labels are prefixed with @, as in queries, to tell them from the due column, see parseItemLine.
+	for j := range labelNames {
+		labelNames[j] = "@" + labelNames[j]
+	}
+	dueIn := ""
+	if !due.IsZero() {
+		dueIn = relativeDurationFormat(time.Until(due))
+	}
+	_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t(%d) %s%v\n", i.ID, todoist.PriorityName(i.Priority), strings.Join(labelNames, " "), dueIn, i.ChildOrder, strings.Repeat(indentation, depth), i.Content)
+	return nil
+}
+
 func printItemByID(w io.Writer, id int64) error {
 	item, ok := client.ItemByID(id)
 	if !ok {
@@ -111,8 +316,16 @@ func printItem(w io.Writer, item *todoist.Item) error {
 	_, _ = fmt.Fprintf(w, "Content: %s\n", item.Content)
 	_, _ = fmt.Fprintf(w, "Project: %s\n", projectName)
 	_, _ = fmt.Fprintf(w, "Labels: %s\n", strings.Join(labelNames, " "))
+	_, _ = fmt.Fprintf(w, "Priority: %s\n", todoist.PriorityName(item.Priority))
 	if item.Due != nil {
-		_, _ = fmt.Fprintf(w, "Due: %s\n", item.Due.Date)
+		// The due string, if any, is what the user typed, or how Todoist describes the due date.
+		s := item.Due.String
+		if s == "" {
+			s = item.Due.Date
+		}
+		_, _ = fmt.Fprintf(w, "Due: %s\n", s)
+		_, _ = fmt.Fprintf(w, "Due date: %s\n", dueDateFormat(item.Due))
+		_, _ = fmt.Fprintf(w, "Recurring: %v\n", item.Due.IsRecurring)
 	} else {
 		_, _ = fmt.Fprint(w, "Due: \n")
 	}
@@ -135,6 +348,19 @@ func printItem(w io.Writer, item *todoist.Item) error {
 	return nil
 }
 
+// dueDateFormat formats the due date in the user's timezone, e.g., "Mon 2020-01-06 09:00", or without the time for
+// all-day due dates.
+func dueDateFormat(due *todoist.Due) string {
+	t := due.TimeIn(client.Location())
+	if t.IsZero() {
+		return "unknown until Todoist parses the due string"
+	}
+	if due.IsAllDay() {
+		return t.Format("Mon 2006-01-02")
+	}
+	return t.Format("Mon 2006-01-02 15:04")
+}
+
 func printNewItemForProject(w io.Writer, projectID int64) error {
 	project, err := getProjectName(projectID)
 	if err != nil {
@@ -148,6 +374,7 @@ func printNewItemForProject(w io.Writer, projectID int64) error {
 	_, _ = fmt.Fprintf(w, `Content: 
 Project: %s
 Labels: 
+Priority: p4
 Due: 
 Note: 
 Available labels: %s
@@ -175,27 +402,3 @@ func getLabelNames(ids []int64) ([]string, error) {
 	sort.Strings(names)
 	return names, nil
 }
-
-func addSearchTerm(s *todoist.ItemScan, term string) {
-	switch term[0] {
-	case '-':
-		addSearchTerm(s, term[1:])
-		s.Not()
-	case '@':
-		label := client.LabelByName(term[1:])
-		if label != nil {
-			s.WithLabel(label.ID)
-		} else {
-			// This won't match any item.
-			s.WithLabel(0)
-		}
-	case '#':
-		var pids []int64
-		for _, p := range client.SearchProjects().WithIsArchived(0).WithIsDeleted(0).WithName(term[1:]).Results() {
-			pids = append(pids, p.ID)
-		}
-		s.WithProjectID(pids...)
-	default:
-		s.WithContent(term)
-	}
-}
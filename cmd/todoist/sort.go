@@ -23,10 +23,11 @@ func (items itemsByDue) Less(i, j int) bool {
 	if a == nil && b != nil {
 		return false
 	}
-	if a.Time().Unix() == b.Time().Unix() {
+	loc := client.Location()
+	if a.TimeIn(loc).Unix() == b.TimeIn(loc).Unix() {
 		return items[i].ID < items[j].ID
 	}
-	return a.Time().Before(b.Time())
+	return a.TimeIn(loc).Before(b.TimeIn(loc))
 }
 
 type notesByPosted []*todoist.Note
@@ -43,30 +44,16 @@ func (notes notesByPosted) Less(i, j int) bool {
 	return notes[i].Time().Before(notes[j].Time())
 }
 
-type itemsByChildOrder []*todoist.Item
+type sectionsBySectionOrder []*todoist.Section
 
-func (items itemsByChildOrder) Len() int {
-	return len(items)
-}
-
-func (items itemsByChildOrder) Swap(i, j int) {
-	items[i], items[j] = items[j], items[i]
-}
-
-func (items itemsByChildOrder) Less(i, j int) bool {
-	return items[i].ChildOrder < items[j].ChildOrder
-}
-
-type projectsByChildOrder []*todoist.Project
-
-func (projects projectsByChildOrder) Len() int {
-	return len(projects)
+func (sections sectionsBySectionOrder) Len() int {
+	return len(sections)
 }
 
-func (projects projectsByChildOrder) Swap(i, j int) {
-	projects[i], projects[j] = projects[j], projects[i]
+func (sections sectionsBySectionOrder) Swap(i, j int) {
+	sections[i], sections[j] = sections[j], sections[i]
 }
 
-func (projects projectsByChildOrder) Less(i, j int) bool {
-	return projects[i].ChildOrder < projects[j].ChildOrder
+func (sections sectionsBySectionOrder) Less(i, j int) bool {
+	return sections[i].SectionOrder < sections[j].SectionOrder
 }
@@ -4,41 +4,62 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	uuid "github.com/nu7hatch/gouuid"
 )
 
 // These constants are among the possible values for the type property of a command.
 const (
-	itemAdd     = "item_add"
-	itemUpdate  = "item_update"
-	itemDelete  = "item_delete"
-	itemClose   = "item_close"
-	itemMove    = "item_move"
-	itemReorder = "item_reorder"
+	itemAdd        = "item_add"
+	itemUpdate     = "item_update"
+	itemDelete     = "item_delete"
+	itemClose      = "item_close"
+	itemUncomplete = "item_uncomplete"
+	itemMove       = "item_move"
+	itemReorder    = "item_reorder"
 
 	labelAdd    = "label_add"
 	labelUpdate = "label_update"
 	labelDelete = "label_delete"
 
-	projectAdd     = "project_add"
-	projectUpdate  = "project_update"
-	projectDelete  = "project_delete"
-	projectArchive = "project_archive"
-	projectReorder = "project_reorder"
+	projectAdd       = "project_add"
+	projectUpdate    = "project_update"
+	projectDelete    = "project_delete"
+	projectArchive   = "project_archive"
+	projectUnarchive = "project_unarchive"
+	projectReorder   = "project_reorder"
+	projectMove      = "project_move"
+
+	sectionAdd     = "section_add"
+	sectionUpdate  = "section_update"
+	sectionMove    = "section_move"
+	sectionReorder = "section_reorder"
+	sectionArchive = "section_archive"
+	sectionDelete  = "section_delete"
+
+	reminderAdd    = "reminder_add"
+	reminderUpdate = "reminder_update"
+	reminderDelete = "reminder_delete"
+
+	filterAdd          = "filter_add"
+	filterUpdate       = "filter_update"
+	filterDelete       = "filter_delete"
+	filterUpdateOrders = "filter_update_orders"
 
 	noteAdd    = "note_add"
 	noteUpdate = "note_update"
 	noteDelete = "note_delete"
 )
 
-// entityOrderAssignment can be used for items and projects alike.
+// entityOrderAssignment can be used for items, projects, and sections alike. (The order property of sections is
+// called section_order, see ReorderCommand.MarshalJSON.)
 type entityOrderAssignment struct {
 	ID         int64 `json:"id"`
 	ChildOrder int   `json:"child_order"`
 }
 
-// ReorderCommand is for reordering both projects and items.
+// ReorderCommand is for reordering projects, items, sections, or filters.
 type ReorderCommand struct {
 	entity string
 	args   []entityOrderAssignment
@@ -65,6 +86,17 @@ func (reorder *ReorderCommand) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	switch reorder.entity {
+	case "sections":
+		b = bytes.ReplaceAll(b, []byte(`"child_order":`), []byte(`"section_order":`))
+	case "filters":
+		// Filters are reordered with a map from ids to orders.
+		mapping := make(map[string]int, len(reorder.args))
+		for _, a := range reorder.args {
+			mapping[strconv.FormatInt(a.ID, 10)] = a.ChildOrder
+		}
+		return json.Marshal(map[string]interface{}{"id_order_mapping": mapping})
+	}
 	buf := bytes.NewBuffer(nil)
 	_, _ = fmt.Fprintf(buf, "{%q:", reorder.entity)
 	buf.Write(b)
@@ -86,13 +118,33 @@ type command struct {
 	// commands
 	// It can be many more things but they're not implemented in this package.
 	Args interface{} `json:"args"`
+
+	// Set if the servers rejected the command, see PushError. Such commands are not sent again, so this is not
+	// part of the wire format, but it's persisted with the queue.
+	Err *Error `json:"error,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It's needed to restore the queue of commands in Load. The args are kept
+// in their JSON form, which is all that's needed to send the command again.
+func (c *command) UnmarshalJSON(b []byte) error {
+	type plain command
+	var raw struct {
+		*plain
+		Args json.RawMessage `json:"args"`
+	}
+	raw.plain = (*plain)(c)
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	c.Args = raw.Args
+	return nil
 }
 
 func newCommand(cmdType string, args interface{}) *command {
 	u, _ := uuid.NewV4()
 	c := &command{Type: cmdType, UUID: u.String(), Args: args}
 	switch cmdType {
-	case itemAdd, labelAdd, noteAdd, projectAdd:
+	case itemAdd, labelAdd, noteAdd, projectAdd, sectionAdd, reminderAdd, filterAdd:
 		u, _ := uuid.NewV4()
 		c.TempID = u.String()
 	default:
@@ -100,90 +152,230 @@ func newCommand(cmdType string, args interface{}) *command {
 	return c
 }
 
+// enqueue appends a command to the queue of commands to be sent with the next Push, and applies it to the
+// client's view of the data.
+func (c *Client) enqueue(cmd *command) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cmd.TempID != "" {
+		c.allocateLocalID(cmd.TempID)
+	}
+	c.data.Commands = append(c.data.Commands, cmd)
+	c.applyLogged(c.view, cmd)
+}
+
 func (c *Client) QueueItemAdd(item *ItemPatch) (temporaryID string) {
 	add := newCommand(itemAdd, item)
-	c.commands = append(c.commands, add)
+	c.enqueue(add)
 	return add.TempID
 }
 
 func (c *Client) QueueItemUpdate(item *ItemPatch) {
-	c.commands = append(c.commands, newCommand(itemUpdate, item))
+	c.enqueue(newCommand(itemUpdate, item))
 }
 
 func (c *Client) QueueItemDelete(id int64) {
-	c.commands = append(c.commands, newCommand(itemDelete, idContainer{ID: id}))
+	c.enqueue(newCommand(itemDelete, idContainer{ID: id}))
 }
 
+// QueueItemClose completes an item, like the check box in the apps: recurring items are moved to their next
+// occurrence rather than completed.
 func (c *Client) QueueItemClose(id int64) {
-	c.commands = append(c.commands, newCommand(itemClose, idContainer{ID: id}))
+	c.enqueue(newCommand(itemClose, idContainer{ID: id}))
 }
 
-// itemMoveCommand represents a command to move an item to another project.  (The project id property can not be
-// set as part of an item update (which would achieve moving the project). This is just how the Todoist APIs work.)
+// QueueItemUncomplete marks a completed item as not completed. See also SearchCompleted.
+func (c *Client) QueueItemUncomplete(id int64) {
+	c.enqueue(newCommand(itemUncomplete, idContainer{ID: id}))
+}
+
+// itemMoveCommand represents a command to move an item to another project or section, or under another item.  (The
+// project, section, and parent id properties can not be set as part of an item update (which would achieve moving
+// the item). This is just how the Todoist APIs work.) Exactly one of the destination properties must be set.
 type itemMoveCommand struct {
-	ID        ID `json:"id"`
-	ProjectID ID `json:"project_id"`
+	ID        ID  `json:"id"`
+	ProjectID *ID `json:"project_id,omitempty"`
+	ParentID  *ID `json:"parent_id,omitempty"`
+	SectionID *ID `json:"section_id,omitempty"`
 }
 
+// QueueItemMove moves the item, and its sub-tasks, to the top level of the given project.
 func (c *Client) QueueItemMove(item, project ID) {
-	c.commands = append(c.commands, newCommand(itemMove, &itemMoveCommand{
+	c.enqueue(newCommand(itemMove, &itemMoveCommand{
 		ID:        item,
-		ProjectID: project,
+		ProjectID: &project,
+	}))
+}
+
+// QueueItemMoveToParent makes the item a sub-task of the given parent item, possibly in another project. The
+// item's sub-tasks move along with it. To move an item back to the top level, use QueueItemMove.
+func (c *Client) QueueItemMoveToParent(item, parent ID) {
+	c.enqueue(newCommand(itemMove, &itemMoveCommand{
+		ID:       item,
+		ParentID: &parent,
+	}))
+}
+
+// QueueItemMoveToSection moves the item, and its sub-tasks, to the top level of the given section, possibly in
+// another project.
+func (c *Client) QueueItemMoveToSection(item, section ID) {
+	c.enqueue(newCommand(itemMove, &itemMoveCommand{
+		ID:        item,
+		SectionID: &section,
 	}))
 }
 
 func (c *Client) QueueItemReorder(reorder *ReorderCommand) {
 	reorder.entity = "items"
-	c.commands = append(c.commands, newCommand(itemReorder, reorder))
+	c.enqueue(newCommand(itemReorder, reorder))
 }
 
 func (c *Client) QueueLabelAdd(label *LabelPatch) (temporaryID string) {
 	add := newCommand(labelAdd, label)
-	c.commands = append(c.commands, add)
+	c.enqueue(add)
 	return add.TempID
 }
 
 func (c *Client) QueueLabelUpdate(label *LabelPatch) {
-	c.commands = append(c.commands, newCommand(labelUpdate, label))
+	c.enqueue(newCommand(labelUpdate, label))
 }
 
 func (c *Client) QueueLabelDelete(id int64) {
-	c.commands = append(c.commands, newCommand(labelDelete, idContainer{ID: id}))
+	c.enqueue(newCommand(labelDelete, idContainer{ID: id}))
 }
 
 func (c *Client) QueueProjectAdd(project *ProjectPatch) (temporaryID string) {
 	add := newCommand(projectAdd, project)
-	c.commands = append(c.commands, add)
+	c.enqueue(add)
 	return add.TempID
 }
 
 func (c *Client) QueueProjectUpdate(project *ProjectPatch) {
-	c.commands = append(c.commands, newCommand(projectUpdate, project))
+	c.enqueue(newCommand(projectUpdate, project))
 }
 
 func (c *Client) QueueProjectArchive(id int64) {
-	c.commands = append(c.commands, newCommand(projectArchive, idContainer{ID: id}))
+	c.enqueue(newCommand(projectArchive, idContainer{ID: id}))
+}
+
+// QueueProjectUnarchive restores an archived project. See also ArchivedProjects.
+func (c *Client) QueueProjectUnarchive(id int64) {
+	c.enqueue(newCommand(projectUnarchive, idContainer{ID: id}))
 }
 
 func (c *Client) QueueProjectDelete(id int64) {
-	c.commands = append(c.commands, newCommand(projectDelete, idContainer{ID: id}))
+	c.enqueue(newCommand(projectDelete, idContainer{ID: id}))
+}
+
+// projectMoveCommand is analogous to itemMoveCommand. A nil parent id moves the project to the top level.
+type projectMoveCommand struct {
+	ID       ID  `json:"id"`
+	ParentID *ID `json:"parent_id"`
+}
+
+// QueueProjectMove makes the project a sub-project of the given parent project. Its sub-projects move along with
+// it.
+func (c *Client) QueueProjectMove(project, parent ID) {
+	c.enqueue(newCommand(projectMove, &projectMoveCommand{
+		ID:       project,
+		ParentID: &parent,
+	}))
+}
+
+// QueueProjectMoveToTop moves the project, and its sub-projects, to the top level.
+func (c *Client) QueueProjectMoveToTop(project ID) {
+	c.enqueue(newCommand(projectMove, &projectMoveCommand{
+		ID: project,
+	}))
 }
 
 func (c *Client) QueueProjectReorder(reorder *ReorderCommand) {
 	reorder.entity = "projects"
-	c.commands = append(c.commands, newCommand(projectReorder, reorder))
+	c.enqueue(newCommand(projectReorder, reorder))
+}
+
+func (c *Client) QueueSectionAdd(section *SectionPatch) (temporaryID string) {
+	add := newCommand(sectionAdd, section)
+	c.enqueue(add)
+	return add.TempID
+}
+
+func (c *Client) QueueSectionUpdate(section *SectionPatch) {
+	c.enqueue(newCommand(sectionUpdate, section))
+}
+
+// sectionMoveCommand is analogous to itemMoveCommand.
+type sectionMoveCommand struct {
+	ID        ID `json:"id"`
+	ProjectID ID `json:"project_id"`
+}
+
+// QueueSectionMove moves the section, and its items, to another project.
+func (c *Client) QueueSectionMove(section, project ID) {
+	c.enqueue(newCommand(sectionMove, &sectionMoveCommand{
+		ID:        section,
+		ProjectID: project,
+	}))
+}
+
+func (c *Client) QueueSectionReorder(reorder *ReorderCommand) {
+	reorder.entity = "sections"
+	c.enqueue(newCommand(sectionReorder, reorder))
+}
+
+// QueueSectionArchive archives the section. Its items are archived along with it by the servers.
+func (c *Client) QueueSectionArchive(id int64) {
+	c.enqueue(newCommand(sectionArchive, idContainer{ID: id}))
+}
+
+// QueueSectionDelete deletes the section and all its items.
+func (c *Client) QueueSectionDelete(id int64) {
+	c.enqueue(newCommand(sectionDelete, idContainer{ID: id}))
+}
+
+func (c *Client) QueueReminderAdd(reminder *ReminderPatch) (temporaryID string) {
+	add := newCommand(reminderAdd, reminder)
+	c.enqueue(add)
+	return add.TempID
+}
+
+func (c *Client) QueueReminderUpdate(reminder *ReminderPatch) {
+	c.enqueue(newCommand(reminderUpdate, reminder))
+}
+
+func (c *Client) QueueReminderDelete(id int64) {
+	c.enqueue(newCommand(reminderDelete, idContainer{ID: id}))
+}
+
+func (c *Client) QueueFilterAdd(filter *FilterPatch) (temporaryID string) {
+	add := newCommand(filterAdd, filter)
+	c.enqueue(add)
+	return add.TempID
+}
+
+func (c *Client) QueueFilterUpdate(filter *FilterPatch) {
+	c.enqueue(newCommand(filterUpdate, filter))
+}
+
+func (c *Client) QueueFilterDelete(id int64) {
+	c.enqueue(newCommand(filterDelete, idContainer{ID: id}))
+}
+
+func (c *Client) QueueFilterReorder(reorder *ReorderCommand) {
+	reorder.entity = "filters"
+	c.enqueue(newCommand(filterUpdateOrders, reorder))
 }
 
 func (c *Client) QueueNoteAdd(note *NotePatch) (temporaryID string) {
 	add := newCommand(noteAdd, note)
-	c.commands = append(c.commands, add)
+	c.enqueue(add)
 	return add.TempID
 }
 
 func (c *Client) QueueNoteUpdate(note *NotePatch) {
-	c.commands = append(c.commands, newCommand(noteUpdate, note))
+	c.enqueue(newCommand(noteUpdate, note))
 }
 
 func (c *Client) QueueNoteDelete(id int64) {
-	c.commands = append(c.commands, newCommand(noteDelete, idContainer{ID: id}))
+	c.enqueue(newCommand(noteDelete, idContainer{ID: id}))
 }
@@ -0,0 +1,146 @@
+package todoist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CompletedItem describes the completion of an item, as returned by the completed items endpoint (see
+// FetchCompleted). Treat as read-only.
+type CompletedItem struct {
+	ID            int64  `json:"id"`      // Identifies the completion, not the item, see ItemID.
+	ItemID        int64  `json:"task_id"` // The item that was completed.
+	ProjectID     int64  `json:"project_id"`
+	SectionID     int64  `json:"section_id"`
+	Content       string `json:"content"`
+	CompletedDate string `json:"completed_date"` // In RFC 3339 format, see CompletedTime.
+	NoteCount     int    `json:"note_count"`
+}
+
+// CompletedTime parses the CompletedDate property. It returns the zero time if the property is not in RFC 3339
+// format.
+func (item *CompletedItem) CompletedTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, item.CompletedDate)
+	return t
+}
+
+// completedPageSize is the maximum number of completed items the servers return per call.
+const completedPageSize = 200
+
+// completedResponse partially represents the response from the completed items endpoint.
+type completedResponse struct {
+	Items []*CompletedItem `json:"items"`
+}
+
+// FetchCompleted fetches the items completed between since and until (the zero time meaning no bound) from their
+// own endpoint, as the sync API doesn't return them. They're kept apart from the other items, see SearchCompleted,
+// and saved along with the rest of the client's data. Fetching the same period twice doesn't duplicate them.
+func (c *Client) FetchCompleted(since, until time.Time) ([]*CompletedItem, error) {
+	return c.FetchCompletedContext(context.Background(), since, until)
+}
+
+// FetchCompletedContext is like FetchCompleted, but the remote calls are bound to the given context.
+func (c *Client) FetchCompletedContext(ctx context.Context, since, until time.Time) ([]*CompletedItem, error) {
+	endpoint, err := c.resolveEndpoint("completed/get_all")
+	if err != nil {
+		return nil, fmt.Errorf("completed items: %w", err)
+	}
+	data := make(url.Values)
+	data.Set("token", c.token)
+	data.Set("limit", strconv.Itoa(completedPageSize))
+	// The servers don't accept seconds nor time zones.
+	const layout = "2006-01-02T15:04"
+	if !since.IsZero() {
+		data.Set("since", since.UTC().Format(layout))
+	}
+	if !until.IsZero() {
+		data.Set("until", until.UTC().Format(layout))
+	}
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	var completed []*CompletedItem
+	for {
+		data.Set("offset", strconv.Itoa(len(completed)))
+		b, err := c.post(ctx, "completed items", endpoint, data)
+		if err != nil {
+			return nil, err
+		}
+		var cr completedResponse
+		if err := json.Unmarshal(b, &cr); err != nil {
+			return nil, fmt.Errorf("completed items, unmarshal: %w", err)
+		}
+		completed = append(completed, cr.Items...)
+		if len(cr.Items) < completedPageSize {
+			break
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, item := range completed {
+		c.data.Completed[item.ID] = item
+	}
+	c.refreshView()
+	return completed, nil
+}
+
+type completedPredicate func(*CompletedItem) bool
+
+// CompletedScan searches the completed items fetched with FetchCompleted.
+type CompletedScan struct {
+	client     *Client
+	predicates []completedPredicate
+}
+
+// WithProjectID looks for items completed in any of the given projects.
+func (s *CompletedScan) WithProjectID(value ...int64) *CompletedScan {
+	s.predicates = append(s.predicates, func(item *CompletedItem) bool {
+		for _, pid := range value {
+			if item.ProjectID == pid {
+				return true
+			}
+		}
+		return false
+	})
+	return s
+}
+
+// WithCompletedBetween looks for items completed in the given period, see ItemScan.WithAddedBetween.
+func (s *CompletedScan) WithCompletedBetween(from, to time.Time) *CompletedScan {
+	s.predicates = append(s.predicates, func(item *CompletedItem) bool {
+		return within(item.CompletedTime(), from, to)
+	})
+	return s
+}
+
+func (s *CompletedScan) Results() []*CompletedItem {
+	s.client.mu.RLock()
+	defer s.client.mu.RUnlock()
+	var results []*CompletedItem
+	for _, item := range s.client.view.Completed {
+		if s.match(item) {
+			results = append(results, item)
+		}
+	}
+	return results
+}
+
+func (s *CompletedScan) match(item *CompletedItem) bool {
+	for _, match := range s.predicates {
+		if !match(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchCompleted searches the completed items fetched with FetchCompleted. Items completed by this client are
+// only found after fetching them. Items uncompleted with QueueItemUncomplete are not found.
+func (c *Client) SearchCompleted() *CompletedScan {
+	return &CompletedScan{
+		client: c,
+	}
+}
@@ -0,0 +1,75 @@
+package todoist_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCompletedServer serves the given number of completed items, one per hour, in pages, and a pull response with
+// an archived item.
+func newCompletedServer(total int) *httptest.Server {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/completed/get_all" {
+			_, _ = fmt.Fprint(w, `{"sync_token": "t", "items": [{"id": 1, "project_id": 10, "content": "done", "checked": 1}]}`)
+			return
+		}
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+		var items []map[string]interface{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			items = append(items, map[string]interface{}{
+				"id":             100 + i,
+				"task_id":        1 + i,
+				"project_id":     10 + i%2,
+				"content":        fmt.Sprintf("task %d", i),
+				"completed_date": start.Add(time.Duration(i) * time.Hour).Format(time.RFC3339),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+}
+
+func TestFetchCompleted(t *testing.T) {
+	ts := newCompletedServer(250)
+	defer ts.Close()
+	store := todoist.NewMemoryStore()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL+"/sync"), todoist.WithStore(store),
+		todoist.WithCompletedArchive())
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	completed, err := c.FetchCompleted(time.Time{}, time.Time{})
+	require.Nil(t, err)
+	assert.Len(t, completed, 250)
+	_, err = c.FetchCompleted(time.Time{}, time.Time{})
+	require.Nil(t, err)
+	assert.Len(t, c.SearchCompleted().Results(), 250)
+	assert.Len(t, c.SearchCompleted().WithProjectID(10).Results(), 125)
+	from := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	assert.Len(t, c.SearchCompleted().WithCompletedBetween(from, from.Add(24*time.Hour)).Results(), 24)
+
+	// Completed items survive a dump and load.
+	require.Nil(t, c.Dump())
+	c, err = todoist.NewClient("token", todoist.WithEndpoint(ts.URL+"/sync"), todoist.WithStore(store),
+		todoist.WithCompletedArchive())
+	require.Nil(t, err)
+	require.Nil(t, c.Load())
+	assert.Len(t, c.SearchCompleted().Results(), 250)
+
+	c.QueueItemUncomplete(1)
+	assert.Len(t, c.SearchCompleted().Results(), 249)
+	assert.Empty(t, c.SearchArchive().Results())
+	item, ok := c.ItemByID(1)
+	require.True(t, ok)
+	assert.Equal(t, 0, item.Checked)
+}
@@ -13,5 +13,6 @@
 // the previous time it was called, including locally initiated changes (the first time, it will download all the data).
 //
 // Methods that query the data, e.g., ItemByID or SearchProjects, use the local copy of the data.  Methods that
-// modify the data, e.g., QueueItemAdd, locally enqueue the changes to be later sent upstream by Push.
+// modify the data, e.g., QueueItemAdd, locally enqueue the changes to be later sent upstream by Push, and apply them
+// to the local copy of the data right away.
 package todoist // import "github.com/nicolagi/todoist"
@@ -1,18 +1,37 @@
 package todoist
 
 import (
+	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// Layouts of Due.Date for each kind of due date.
+const (
+	allDayLayout    = "2006-01-02"
+	floatingLayout  = "2006-01-02T15:04:05"
+	fixedZoneLayout = "2006-01-02T15:04:05Z"
+)
+
+// Due says when an item is due. There are three kinds of due dates:
+//
+//   - all-day, with a Date such as 2020-01-02, due some time in that day, in the user's timezone;
+//   - floating, with a Date such as 2020-01-02T15:00:00, due at that wall-clock time in the user's timezone,
+//     whatever that is at the time;
+//   - fixed-zone, with a Date such as 2020-01-02T14:00:00Z, always in UTC, due at that instant, and a Timezone
+//     such as Europe/Rome that's only relevant to display the time and for recurring due dates.
+//
+// The user's timezone is given by Client.Location. Use DueOn, DueFloating, DueAt, or ParseDue to set due dates with
+// ItemPatch.WithDue.
 type Due struct {
 	// From v8 API doc: Due date in the format of YYYY-MM-DD (RFC 3339). For recurring dates, the date of the
 	// current iteration.
 	Date string `json:"date"`
 
-	// From v8 API doc: Always set to null.
+	// From v8 API doc: Always set to null.  (In practice, it's set for fixed-zone due dates.)
 	Timezone string `json:"timezone"`
 
 	// From v8 API doc: Human-readable representation of due date. String always represents the due object in
@@ -25,30 +44,115 @@ type Due struct {
 	Lang string `json:"lang"`
 
 	IsRecurring bool `json:"is_recurring"`
+}
 
-	// This will be lazily populated by parsing the Date property.
-	time time.Time
+// DueOn returns an all-day due date for the day of t, in t's location.
+func DueOn(t time.Time) Due {
+	return Due{Date: t.Format(allDayLayout)}
 }
 
-func (due Due) Time() time.Time {
-	if !due.time.IsZero() {
-		return due.time
+// DueFloating returns a floating due date for the wall-clock time of t, in t's location.
+func DueFloating(t time.Time) Due {
+	return Due{Date: t.Format(floatingLayout)}
+}
+
+// DueAt returns a fixed-zone due date for the instant t. The name of t's location is used as timezone, unless it's
+// the local timezone, which has no portable name.
+func DueAt(t time.Time) Due {
+	due := Due{Date: t.UTC().Format(fixedZoneLayout)}
+	if name := t.Location().String(); name != "Local" {
+		due.Timezone = name
+	}
+	return due
+}
+
+// ParseDue parses a due date in one of the forms of Due.Date, e.g., 2020-01-02, 2020-01-02T15:00:00, or
+// 2020-01-02T14:00:00Z. Times with an offset, e.g., 2020-01-02T15:00:00+01:00, make fixed-zone due dates too.
+func ParseDue(s string) (Due, error) {
+	if t, err := time.Parse(allDayLayout, s); err == nil {
+		return DueOn(t), nil
 	}
-	date := due.Date
-	if !strings.Contains(date, "T") {
-		date += "T23:59:59Z"
+	if t, err := time.Parse(floatingLayout, s); err == nil {
+		return DueFloating(t), nil
 	}
-	if !strings.HasSuffix(date, "Z") {
-		// I've seen sometimes "2019-08-23T10:00:00", without trailing "Z".
-		date += "Z"
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return Due{Date: t.UTC().Format(fixedZoneLayout)}, nil
 	}
+	return Due{}, fmt.Errorf("due date %q: want YYYY-MM-DD, YYYY-MM-DDThh:mm:ss, or RFC 3339", s)
+}
+
+// IsAllDay reports whether the due date has no time.
+func (due Due) IsAllDay() bool {
+	return !strings.Contains(due.Date, "T")
+}
+
+// IsFloating reports whether the due date has a time but no timezone.
+func (due Due) IsFloating() bool {
+	return !due.IsAllDay() && !strings.HasSuffix(due.Date, "Z")
+}
+
+// TimeIn returns when the item is due, interpreting all-day and floating due dates in the given location, which
+// should be the user's timezone (see Client.Location). All-day due dates are taken to be due at the end of the day,
+// i.e., at 23:59:59. Fixed-zone due dates are returned in the given location too. It returns the zero time if the
+// date is not known yet, i.e., for a due string not yet parsed by the servers.
+func (due Due) TimeIn(loc *time.Location) time.Time {
+	if due.Date == "" {
+		return time.Time{}
+	}
+	var t time.Time
 	var err error
-	due.time, err = time.Parse(time.RFC3339, date)
+	switch {
+	case due.IsAllDay():
+		t, err = time.ParseInLocation(allDayLayout, due.Date, loc)
+		t = t.Add(24*time.Hour - time.Second)
+	case due.IsFloating():
+		t, err = time.ParseInLocation(floatingLayout, due.Date, loc)
+	default:
+		t, err = time.Parse(time.RFC3339, due.Date)
+		t = t.In(loc)
+	}
 	if err != nil {
 		log.WithFields(log.Fields{
 			"cause": err,
 			"date":  due.Date,
 		}).Warning("Could not parse time, has Todoist changed format?")
 	}
-	return due.time
+	return t
+}
+
+// Time is like TimeIn, for the local timezone of the computer.
+func (due Due) Time() time.Time {
+	return due.TimeIn(time.Local)
+}
+
+// Day returns the midnight starting the day the item is due, in the given location.
+func (due Due) Day(loc *time.Location) time.Time {
+	t := due.TimeIn(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// Reschedule returns the due date moved to the day of the given time, keeping the time of the day, if any, and the
+// timezone of fixed-zone due dates. The result is not recurring: the servers would recompute the date from a
+// recurring due string. The location should be the user's timezone.
+func (due Due) Reschedule(day time.Time, loc *time.Location) Due {
+	zone := due.zone(loc)
+	t := due.TimeIn(zone)
+	t = time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, zone)
+	switch {
+	case due.IsAllDay():
+		return DueOn(t)
+	case due.IsFloating():
+		return DueFloating(t)
+	default:
+		return Due{Date: t.UTC().Format(fixedZoneLayout), Timezone: due.Timezone}
+	}
+}
+
+// args returns the JSON representation of the due date as an argument of commands.
+func (due Due) args() string {
+	b, _ := json.Marshal(struct {
+		Date     string `json:"date"`
+		Timezone string `json:"timezone,omitempty"`
+	}{due.Date, due.Timezone})
+	return string(b)
 }
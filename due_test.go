@@ -0,0 +1,128 @@
+package todoist_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDueTimes(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	require.Nil(t, err)
+	testCases := []struct {
+		name     string
+		due      todoist.Due
+		allDay   bool
+		floating bool
+		expected time.Time
+		day      time.Time
+	}{
+		{
+			name:     "all-day",
+			due:      todoist.Due{Date: "2020-01-02"},
+			allDay:   true,
+			expected: time.Date(2020, 1, 2, 23, 59, 59, 0, rome),
+			day:      time.Date(2020, 1, 2, 0, 0, 0, 0, rome),
+		},
+		{
+			name:     "floating",
+			due:      todoist.Due{Date: "2020-01-02T15:00:00"},
+			floating: true,
+			expected: time.Date(2020, 1, 2, 15, 0, 0, 0, rome),
+			day:      time.Date(2020, 1, 2, 0, 0, 0, 0, rome),
+		},
+		{
+			name:     "fixed-zone",
+			due:      todoist.Due{Date: "2020-01-02T23:30:00Z", Timezone: "Europe/Rome"},
+			expected: time.Date(2020, 1, 3, 0, 30, 0, 0, rome),
+			day:      time.Date(2020, 1, 3, 0, 0, 0, 0, rome),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.allDay, tc.due.IsAllDay())
+			assert.Equal(t, tc.floating, tc.due.IsFloating())
+			assert.True(t, tc.expected.Equal(tc.due.TimeIn(rome)), "%v", tc.due.TimeIn(rome))
+			assert.True(t, tc.day.Equal(tc.due.Day(rome)), "%v", tc.due.Day(rome))
+		})
+	}
+}
+
+func TestDueConstructors(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	require.Nil(t, err)
+	at := time.Date(2020, 1, 2, 15, 4, 5, 0, rome)
+	assert.Equal(t, todoist.Due{Date: "2020-01-02"}, todoist.DueOn(at))
+	assert.Equal(t, todoist.Due{Date: "2020-01-02T15:04:05"}, todoist.DueFloating(at))
+	assert.Equal(t, todoist.Due{Date: "2020-01-02T14:04:05Z", Timezone: "Europe/Rome"}, todoist.DueAt(at))
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"2020-01-02", "2020-01-02"},
+		{"2020-01-02T15:04:05", "2020-01-02T15:04:05"},
+		{"2020-01-02T14:04:05Z", "2020-01-02T14:04:05Z"},
+		{"2020-01-02T15:04:05+01:00", "2020-01-02T14:04:05Z"},
+	}
+	for _, tc := range testCases {
+		due, err := todoist.ParseDue(tc.input)
+		require.Nil(t, err, tc.input)
+		assert.Equal(t, tc.expected, due.Date)
+	}
+	_, err = todoist.ParseDue("tomorrow")
+	assert.NotNil(t, err)
+
+	b, err := json.Marshal(todoist.NewItemPatch(1).WithDue(todoist.DueAt(at)))
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"id": 1, "due": {"date": "2020-01-02T14:04:05Z", "timezone": "Europe/Rome"}}`, string(b))
+	b, err = json.Marshal(todoist.NewItemPatch(1).WithDue(todoist.DueOn(at)))
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"id": 1, "due": {"date": "2020-01-02"}}`, string(b))
+}
+
+func TestClientLocation(t *testing.T) {
+	ts := newStaticServer(`{"sync_token": "t", "user": {"id": 1, "tz_info": {"timezone": "Asia/Tokyo"}}}`)
+	defer ts.Close()
+
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	assert.Equal(t, time.Local, c.Location())
+	require.Nil(t, c.Pull())
+	assert.Equal(t, "Asia/Tokyo", c.Location().String())
+	user, ok := c.User()
+	require.True(t, ok)
+	assert.Equal(t, int64(1), user.ID)
+
+	// The option wins over the user's settings.
+	c, err = todoist.NewClient("token", todoist.WithEndpoint(ts.URL), todoist.WithTimezone("Europe/Rome"))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+	assert.Equal(t, "Europe/Rome", c.Location().String())
+
+	_, err = todoist.NewClient("token", todoist.WithTimezone("Nowhere/Special"))
+	assert.NotNil(t, err)
+}
+
+func TestDueReschedule(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	require.Nil(t, err)
+	day := time.Date(2020, time.March, 29, 0, 0, 0, 0, rome)
+	testCases := []struct {
+		due      todoist.Due
+		expected todoist.Due
+	}{
+		{todoist.Due{Date: "2020-01-02"}, todoist.Due{Date: "2020-03-29"}},
+		{todoist.Due{Date: "2020-01-02T15:00:00"}, todoist.Due{Date: "2020-03-29T15:00:00"}},
+		// Nine in the morning in Rome, in summer time.
+		{todoist.Due{Date: "2020-01-02T08:00:00Z", Timezone: "Europe/Rome"},
+			todoist.Due{Date: "2020-03-29T07:00:00Z", Timezone: "Europe/Rome"}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.due.Reschedule(day, rome))
+	}
+}
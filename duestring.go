@@ -0,0 +1,239 @@
+package todoist
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDueString previews the due date the servers would set for a due string in English (see
+// ItemPatch.WithDueString), relative to the given time and in its location, which should be the user's timezone (see
+// Client.Location). The servers understand much more; this covers the common forms:
+//
+//	today, tomorrow, yesterday          also tod and tom
+//	monday, next monday                 the first such day after today; also mon, tue, etc.
+//	next week, next month               next monday, and the first day of next month
+//	in 3 days, in 2 weeks               also months and years
+//	jan 3, 3 january, jan 3 2021        month and day, optionally with the year
+//	2021-01-03                          ISO dates
+//	every monday, every 3rd friday      recurring, from the first occurrence from now, see ParseRecurrence
+//
+// Any of the above may be followed or preceded by a time, e.g., "tomorrow 9am", "at 18:30 every day", or just
+// "9:30pm" for today. The result has a floating date if a time is given, an all-day date otherwise.
+func ParseDueString(s string, now time.Time) (Due, error) {
+	due := Due{String: s, Lang: "en"}
+	fail := func() (Due, error) {
+		return Due{}, fmt.Errorf("due string %q: not understood", s)
+	}
+	words, hour, minute, timed, ok := dueWords(s)
+	if !ok {
+		return fail()
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if len(words) > 0 && isRecurring(words[0]) {
+		r, ok := parseRecurrence(words)
+		if !ok {
+			return fail()
+		}
+		r.timed, r.hour, r.minute = timed, hour, minute
+		due.IsRecurring = true
+		if first := r.First(now); timed {
+			due.Date = first.Format(floatingLayout)
+		} else {
+			due.Date = first.Format(allDayLayout)
+		}
+		return due, nil
+	}
+	day, ok := parseDay(words, today)
+	if !ok {
+		return fail()
+	}
+	if timed {
+		due.Date = day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute).Format(floatingLayout)
+	} else {
+		due.Date = day.Format(allDayLayout)
+	}
+	return due, nil
+}
+
+// dueWords splits a due string into lower-case words, commas separating words as spaces do, e.g., in "every mon,
+// wed" or "jan 3, 2021", and extracts the time of the day, see extractTime. It's shared by ParseDueString and
+// ParseRecurrence, so that they understand the same text.
+func dueWords(s string) (words []string, hour, minute int, timed, ok bool) {
+	return extractTime(strings.Fields(strings.ToLower(strings.Replace(s, ",", " ", -1))))
+}
+
+// extractTime removes a time of the day, optionally preceded by "at", from the words of a due string.
+func extractTime(words []string) (rest []string, hour, minute int, timed, ok bool) {
+	for _, w := range words {
+		h, m, isTime := parseTimeOfDay(w)
+		if !isTime {
+			rest = append(rest, w)
+			continue
+		}
+		if timed {
+			return nil, 0, 0, false, false
+		}
+		if len(rest) > 0 && rest[len(rest)-1] == "at" {
+			rest = rest[:len(rest)-1]
+		}
+		hour, minute, timed = h, m, true
+	}
+	return rest, hour, minute, timed, true
+}
+
+// parseTimeOfDay parses times such as 9am, 9:30pm, or 21:30.
+func parseTimeOfDay(w string) (hour, minute int, ok bool) {
+	suffix := ""
+	if strings.HasSuffix(w, "am") || strings.HasSuffix(w, "pm") {
+		suffix, w = w[len(w)-2:], w[:len(w)-2]
+	}
+	hh, mm := w, ""
+	if i := strings.Index(w, ":"); i >= 0 {
+		hh, mm = w[:i], w[i+1:]
+	} else if suffix == "" {
+		// Bare numbers are days, as in "jan 3".
+		return 0, 0, false
+	}
+	hour, err := strconv.Atoi(hh)
+	if err != nil || len(hh) > 2 {
+		return 0, 0, false
+	}
+	if mm != "" {
+		if minute, err = strconv.Atoi(mm); err != nil || len(mm) != 2 || minute > 59 {
+			return 0, 0, false
+		}
+	}
+	switch suffix {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if suffix == "pm" {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return 0, 0, false
+		}
+	}
+	return hour, minute, true
+}
+
+// parseDay parses the words of a non-recurring due string, without the time.
+func parseDay(words []string, today time.Time) (time.Time, bool) {
+	switch len(words) {
+	case 0:
+		return today, true
+	case 1:
+		switch words[0] {
+		case "today", "tod":
+			return today, true
+		case "tomorrow", "tom":
+			return today.AddDate(0, 0, 1), true
+		case "yesterday":
+			return today.AddDate(0, 0, -1), true
+		}
+		if wd, ok := parseWeekday(words[0]); ok {
+			return nextWeekday(today.AddDate(0, 0, 1), wd), true
+		}
+		if t, err := time.ParseInLocation(allDayLayout, words[0], today.Location()); err == nil {
+			return t, true
+		}
+	case 2:
+		if words[0] == "next" {
+			switch words[1] {
+			case "week":
+				return nextWeekday(today.AddDate(0, 0, 1), time.Monday), true
+			case "month":
+				return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), true
+			}
+			if wd, ok := parseWeekday(words[1]); ok {
+				return nextWeekday(today.AddDate(0, 0, 1), wd), true
+			}
+		}
+	case 3:
+		if words[0] == "in" {
+			n, err := strconv.Atoi(words[1])
+			if err != nil {
+				return time.Time{}, false
+			}
+			switch strings.TrimSuffix(words[2], "s") {
+			case "day":
+				return today.AddDate(0, 0, n), true
+			case "week":
+				return today.AddDate(0, 0, 7*n), true
+			case "month":
+				return addMonths(today, n), true
+			case "year":
+				return addMonths(today, 12*n), true
+			}
+			return time.Time{}, false
+		}
+	}
+	return parseMonthDay(words, today)
+}
+
+// parseMonthDay parses dates such as "jan 3", "3 january", or "jan 3 2021". Without the year, it's the first such
+// date from today. Days the month doesn't have, such as "feb 30", are rejected rather than spilling over into the
+// next month.
+func parseMonthDay(words []string, today time.Time) (time.Time, bool) {
+	if len(words) != 2 && len(words) != 3 {
+		return time.Time{}, false
+	}
+	month, ok := parseMonth(words[0])
+	dayWord := words[1]
+	if !ok {
+		if month, ok = parseMonth(words[1]); !ok {
+			return time.Time{}, false
+		}
+		dayWord = words[0]
+	}
+	day, err := strconv.Atoi(strings.TrimRight(dayWord, "stndrh"))
+	// 2000 is a leap year, so this only rules out days that no year has.
+	if err != nil || day < 1 || time.Date(2000, month, day, 0, 0, 0, 0, time.UTC).Month() != month {
+		return time.Time{}, false
+	}
+	if len(words) == 3 {
+		year, err := strconv.Atoi(words[2])
+		if err != nil {
+			return time.Time{}, false
+		}
+		t := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+		return t, t.Month() == month
+	}
+	// Feb 29 may be up to eight years away, e.g., from Mar 2096 to Feb 2104.
+	for year := today.Year(); ; year++ {
+		t := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+		if t.Month() == month && !t.Before(today) {
+			return t, true
+		}
+	}
+}
+
+func parseMonth(w string) (time.Month, bool) {
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if w == name || (len(w) >= 3 && strings.HasPrefix(name, w)) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func parseWeekday(w string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if w == name || (len(w) >= 3 && strings.HasPrefix(name, w)) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// nextWeekday returns the first day with the given weekday on or after the given day.
+func nextWeekday(day time.Time, wd time.Weekday) time.Time {
+	return day.AddDate(0, 0, (int(wd)-int(day.Weekday())+7)%7)
+}
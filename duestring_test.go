@@ -0,0 +1,119 @@
+package todoist_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDueString(t *testing.T) {
+	// A Thursday.
+	now := time.Date(2020, time.January, 2, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		input     string
+		date      string
+		recurring bool
+	}{
+		{"today", "2020-01-02", false},
+		{"Tomorrow", "2020-01-03", false},
+		{"tom 9am", "2020-01-03T09:00:00", false},
+		{"yesterday", "2020-01-01", false},
+		{"monday", "2020-01-06", false},
+		{"thu", "2020-01-09", false},
+		{"next friday at 18:30", "2020-01-03T18:30:00", false},
+		{"next week", "2020-01-06", false},
+		{"next month", "2020-02-01", false},
+		{"in 3 days", "2020-01-05", false},
+		{"in 2 weeks", "2020-01-16", false},
+		{"in 1 month", "2020-02-02", false},
+		{"jan 3", "2020-01-03", false},
+		{"1st jan", "2021-01-01", false},
+		{"March 15 2022 9:30pm", "2022-03-15T21:30:00", false},
+		{"2021-01-03", "2021-01-03", false},
+		{"9pm", "2020-01-02T21:00:00", false},
+		{"every day", "2020-01-02", true},
+		{"daily 9am", "2020-01-03T09:00:00", true},
+		{"every day at 13:00", "2020-01-02T13:00:00", true},
+		{"every monday 9am", "2020-01-06T09:00:00", true},
+		{"every thursday", "2020-01-02", true},
+		{"every month", "2020-01-02", true},
+		{"every mon, wed", "2020-01-06", true},
+		{"jan 3, 2021", "2021-01-03", false},
+		{"feb 29", "2020-02-29", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			due, err := todoist.ParseDueString(tc.input, now)
+			require.Nil(t, err)
+			assert.Equal(t, tc.date, due.Date)
+			assert.Equal(t, tc.recurring, due.IsRecurring)
+			assert.Equal(t, tc.input, due.String)
+		})
+	}
+	for _, input := range []string{"someday", "every", "in a while", "9am 10am", "jan 42", "25:00", "feb 30", "apr 31",
+		"feb 29 2021"} {
+		_, err := todoist.ParseDueString(input, now)
+		assert.NotNil(t, err, input)
+	}
+}
+
+func TestParseDueStringAtMonthEnd(t *testing.T) {
+	testCases := []struct {
+		now   string
+		input string
+		date  string
+	}{
+		{"2020-01-31", "in 1 month", "2020-02-29"},
+		{"2020-01-31", "in 3 months", "2020-04-30"},
+		{"2020-02-29", "in 1 year", "2021-02-28"},
+		{"2020-03-01", "feb 29", "2024-02-29"},
+		{"2020-03-01", "jan 31", "2021-01-31"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.now+" "+tc.input, func(t *testing.T) {
+			now, err := time.Parse("2006-01-02", tc.now)
+			require.Nil(t, err)
+			due, err := todoist.ParseDueString(tc.input, now)
+			require.Nil(t, err)
+			assert.Equal(t, tc.date, due.Date)
+		})
+	}
+}
+
+func TestDueStringPatch(t *testing.T) {
+	b, err := json.Marshal(todoist.NewItemPatch(1).WithDueString("every monday", "en"))
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"id": 1, "due": {"string": "every monday", "lang": "en"}}`, string(b))
+
+	ts := newStaticServer(`{"sync_token": "t", "items": [
+		{"id": 1, "content": "water plants", "due": {"date": "2020-01-02", "string": "every day", "is_recurring": true}},
+		{"id": 2, "content": "pay rent", "due": {"date": "2020-01-02"}}
+	]}`)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL), todoist.WithTimezone("UTC"))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	// The view has the previewed date until the servers parse the string.
+	c.QueueItemUpdate(todoist.NewItemPatch(2).WithDueString("2021-01-03", "en"))
+	item, ok := c.ItemByID(2)
+	require.True(t, ok)
+	assert.Equal(t, "2021-01-03", item.Due.Date)
+	assert.Equal(t, "en", item.Due.Lang)
+	c.QueueItemUpdate(todoist.NewItemPatch(2).WithDueString("when pigs fly", ""))
+	item, _ = c.ItemByID(2)
+	assert.Equal(t, "", item.Due.Date)
+	assert.True(t, item.Due.TimeIn(time.UTC).IsZero())
+
+	// Recurring items are not completed.
+	c.QueueItemClose(1)
+	c.QueueItemClose(2)
+	item, _ = c.ItemByID(1)
+	assert.Equal(t, 0, item.Checked)
+	item, _ = c.ItemByID(2)
+	assert.Equal(t, 1, item.Checked)
+}
@@ -0,0 +1,119 @@
+package todoist
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter partially describes a filter, i.e., a saved query. Treat as read-only, use FilterPatch for add/update
+// commands.
+type Filter struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Query      string `json:"query"` // See https://get.todoist.help/hc/en-us/articles/205248842.
+	Color      int    `json:"color"`
+	ItemOrder  int    `json:"item_order"`
+	IsFavorite int    `json:"is_favorite"`
+	IsDeleted  int    `json:"is_deleted"`
+}
+
+// FilterPatch is used to add or update filters (see, e.g., QueueFilterAdd, QueueFilterUpdate).
+type FilterPatch struct {
+	id    int64
+	attrs map[string]string
+}
+
+func NewFilterPatch(id int64) *FilterPatch {
+	filter := new(FilterPatch)
+	filter.id = id
+	filter.attrs = make(map[string]string)
+	return filter
+}
+
+func (filter *FilterPatch) WithName(value string) *FilterPatch {
+	filter.attrs["name"] = fmt.Sprintf("%q", value)
+	return filter
+}
+
+func (filter *FilterPatch) WithQuery(value string) *FilterPatch {
+	filter.attrs["query"] = fmt.Sprintf("%q", value)
+	return filter
+}
+
+func (filter *FilterPatch) WithColor(value int) *FilterPatch {
+	filter.attrs["color"] = strconv.Itoa(value)
+	return filter
+}
+
+func (filter *FilterPatch) WithItemOrder(value int) *FilterPatch {
+	filter.attrs["item_order"] = strconv.Itoa(value)
+	return filter
+}
+
+func (filter *FilterPatch) WithIsFavorite(value int) *FilterPatch {
+	filter.attrs["is_favorite"] = strconv.Itoa(value)
+	return filter
+}
+
+// MarshalJSON implements json.Marshaler.
+func (filter *FilterPatch) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	_, _ = fmt.Fprintf(buf, `{"id":%d`, filter.id)
+	for k, v := range filter.attrs {
+		_, _ = fmt.Fprintf(buf, `,%q:%s`, k, v)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+type filterPredicate func(*Filter) bool
+
+type FilterScan struct {
+	client     *Client
+	predicates []filterPredicate
+}
+
+// WithName looks for filters containing the given substring, case-insensitive.
+func (s *FilterScan) WithName(needle string) *FilterScan {
+	needle = strings.ToLower(needle)
+	s.predicates = append(s.predicates, func(filter *Filter) bool {
+		return strings.Contains(strings.ToLower(filter.Name), needle)
+	})
+	return s
+}
+
+func (s *FilterScan) WithIsFavorite(value int) *FilterScan {
+	s.predicates = append(s.predicates, func(filter *Filter) bool {
+		return filter.IsFavorite == value
+	})
+	return s
+}
+
+func (s *FilterScan) Results() []*Filter {
+	s.client.mu.RLock()
+	defer s.client.mu.RUnlock()
+	var results []*Filter
+	for _, filter := range s.client.view.Filters {
+		if s.match(filter) {
+			results = append(results, filter)
+		}
+	}
+	return results
+}
+
+func (s *FilterScan) match(filter *Filter) bool {
+	for _, match := range s.predicates {
+		if !match(filter) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Client) SearchFilters() *FilterScan {
+	return &FilterScan{
+		client: c,
+	}
+}
@@ -0,0 +1,68 @@
+package todoist_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pullWithFilters = `{
+	"sync_token": "t",
+	"filters": [
+		{"id": 1, "name": "Urgent", "query": "p1 & today", "item_order": 1},
+		{"id": 2, "name": "Work", "query": "#Work", "item_order": 2, "is_favorite": 1},
+		{"id": 3, "name": "Gone", "query": "overdue", "item_order": 3, "is_deleted": 1}
+	]
+}`
+
+func TestFilterPatch(t *testing.T) {
+	b, err := json.Marshal(todoist.NewFilterPatch(1).WithName("Urgent").WithQuery("p1 | p2").WithItemOrder(3))
+	require.Nil(t, err)
+	var actual map[string]interface{}
+	require.Nil(t, json.Unmarshal(b, &actual))
+	assert.Equal(t, map[string]interface{}{"id": 1.0, "name": "Urgent", "query": "p1 | p2", "item_order": 3.0}, actual)
+}
+
+func TestFilters(t *testing.T) {
+	ts := newStaticServer(pullWithFilters)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	assert.Len(t, c.SearchFilters().Results(), 2)
+	assert.Len(t, c.SearchFilters().WithName("urg").Results(), 1)
+	assert.Len(t, c.SearchFilters().WithIsFavorite(1).Results(), 1)
+
+	c.QueueFilterAdd(todoist.NewFilterPatch(0).WithName("Later").WithQuery("no date"))
+	c.QueueFilterUpdate(todoist.NewFilterPatch(1).WithQuery("p1"))
+	c.QueueFilterDelete(2)
+	reorder := new(todoist.ReorderCommand)
+	reorder.Add(1, 5)
+	c.QueueFilterReorder(reorder)
+	assert.Len(t, c.SearchFilters().Results(), 2)
+	assert.Len(t, c.SearchFilters().WithName("later").Results(), 1)
+	filter, ok := c.FilterByID(1)
+	require.True(t, ok)
+	assert.Equal(t, "p1", filter.Query)
+	assert.Equal(t, 5, filter.ItemOrder)
+	_, ok = c.FilterByID(2)
+	assert.False(t, ok)
+}
+
+func TestFilterReorderMarshaling(t *testing.T) {
+	reorder := new(todoist.ReorderCommand)
+	reorder.Add(1, 2)
+	reorder.Add(3, 1)
+	ts := newStaticServer(`{}`)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	c.QueueFilterReorder(reorder)
+	b, err := json.Marshal(reorder)
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"id_order_mapping": {"1": 2, "3": 1}}`, string(b))
+}
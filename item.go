@@ -5,20 +5,60 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 )
 
 // Item partially describes an item in Todoist. It only includes a subset of the fields.  It is used to deserialize
 // new or updated items in the response to Pull and should be treated as read-only. Mutating client methods use
 // different types, e.g., ItemPatch.
 type Item struct {
-	ID         int64   `json:"id"`
-	ProjectID  int64   `json:"project_id"`
-	Labels     []int64 `json:"labels"`
-	Content    string  `json:"content"`
-	ChildOrder int     `json:"child_order"`
-	Checked    int     `json:"checked"`
-	IsDeleted  int     `json:"is_deleted"`
-	Due        *Due    `json:"due"`
+	ID             int64   `json:"id"`
+	ProjectID      int64   `json:"project_id"`
+	SectionID      int64   `json:"section_id"` // Zero if the item is not in a section.
+	ParentID       int64   `json:"parent_id"`  // Zero if the item is not a sub-task.
+	Labels         []int64 `json:"labels"`
+	Content        string  `json:"content"`
+	Description    string  `json:"description"`
+	Priority       int     `json:"priority"` // From 1 (normal) to 4 (urgent), i.e., the opposite of p1 to p4 in the apps.
+	ChildOrder     int     `json:"child_order"`
+	Collapsed      int     `json:"collapsed"` // Whether the sub-tasks are hidden.
+	Checked        int     `json:"checked"`
+	IsDeleted      int     `json:"is_deleted"`
+	Due            *Due    `json:"due"`
+	DateAdded      string  `json:"date_added"`      // In RFC 3339 format, see AddedTime.
+	DateCompleted  string  `json:"date_completed"`  // In RFC 3339 format, see CompletedTime.
+	AddedByUID     int64   `json:"added_by_uid"`    // The user who created the item.
+	ResponsibleUID int64   `json:"responsible_uid"` // The user the item is assigned to, or zero.
+}
+
+// AddedTime parses the DateAdded property. It returns the zero time if the property is not in RFC 3339 format.
+func (item *Item) AddedTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, item.DateAdded)
+	return t
+}
+
+// CompletedTime is like AddedTime, for the DateCompleted property.
+func (item *Item) CompletedTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, item.DateCompleted)
+	return t
+}
+
+// The apps show priorities as p1 (urgent) to p4 (normal), the opposite of the API, see Item.Priority.
+
+// PriorityName returns the name of the given API priority as shown in the apps, e.g., "p1" for priority 4.
+func PriorityName(priority int) string {
+	if priority < 1 || priority > 4 {
+		priority = 1
+	}
+	return fmt.Sprintf("p%d", 5-priority)
+}
+
+// ParsePriority is the inverse of PriorityName. The name is case-insensitive.
+func ParsePriority(name string) (int, error) {
+	if len(name) != 2 || (name[0] != 'p' && name[0] != 'P') || name[1] < '1' || name[1] > '4' {
+		return 0, fmt.Errorf("priority %q: expected p1, p2, p3, or p4", name)
+	}
+	return 5 - int(name[1]-'0'), nil
 }
 
 // ItemPatch describes an update to an item object. (The setter methods With* might incur an error, which will
@@ -53,6 +93,69 @@ func (item *ItemPatch) WithContent(value string) *ItemPatch {
 	return item
 }
 
+func (item *ItemPatch) WithDescription(value string) *ItemPatch {
+	if item.err != nil {
+		return item
+	}
+	item.attrs["description"] = fmt.Sprintf("%q", value)
+	return item
+}
+
+// WithPriority sets the priority, from 1 (normal) to 4 (urgent).
+func (item *ItemPatch) WithPriority(value int) *ItemPatch {
+	if item.err != nil {
+		return item
+	}
+	item.attrs["priority"] = strconv.Itoa(value)
+	return item
+}
+
+// WithParentID makes the item a sub-task of the given item. It only works when adding items; to change the parent
+// of an existing item, one needs to move it. The id can be a temporary id, see WithLabels.
+func (item *ItemPatch) WithParentID(value ID) *ItemPatch {
+	return item.withID("parent_id", value)
+}
+
+// WithSectionID is like WithParentID, but puts the item in the given section.
+func (item *ItemPatch) WithSectionID(value ID) *ItemPatch {
+	return item.withID("section_id", value)
+}
+
+// WithResponsibleUID assigns the item to the given user, or unassigns it if the user id is zero.
+func (item *ItemPatch) WithResponsibleUID(value int64) *ItemPatch {
+	if item.err != nil {
+		return item
+	}
+	if value == 0 {
+		item.attrs["responsible_uid"] = "null"
+	} else {
+		item.attrs["responsible_uid"] = strconv.FormatInt(value, 10)
+	}
+	return item
+}
+
+// WithCollapsed sets whether the sub-tasks of the item are hidden (1) or shown (0).
+func (item *ItemPatch) WithCollapsed(value int) *ItemPatch {
+	if item.err != nil {
+		return item
+	}
+	item.attrs["collapsed"] = strconv.Itoa(value)
+	return item
+}
+
+func (item *ItemPatch) withID(key string, value ID) *ItemPatch {
+	if item.err != nil {
+		return item
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		item.err = fmt.Errorf("setting %s: %w", key, err)
+	} else {
+		item.attrs[key] = string(b)
+	}
+	return item
+}
+
 func (item *ItemPatch) WithChildOrder(value int) *ItemPatch {
 	if item.err != nil {
 		return item
@@ -81,10 +184,32 @@ func (item *ItemPatch) WithLabels(value ...ID) *ItemPatch {
 	return item
 }
 
-// WithDue sets a due date or date and time, using the RFC3339 format, i.e., in the form 2019-08-07 or
-// 2019-08-07T21:20:34Z.
-func (item *ItemPatch) WithDue(date string) *ItemPatch {
-	item.attrs["due"] = fmt.Sprintf(`{"date":%q}`, date)
+// WithDue sets the due date, see DueOn, DueFloating, DueAt, and ParseDue.
+func (item *ItemPatch) WithDue(due Due) *ItemPatch {
+	if item.err != nil {
+		return item
+	}
+	item.attrs["due"] = due.args()
+	return item
+}
+
+// WithDueString sets the due date as a string for the servers to parse, e.g., "every monday 9am", in the given
+// language, e.g., "en", or in the user's language if empty. Until the next Pull, the client's view has the date
+// previewed by ParseDueString, if it understands the string.
+func (item *ItemPatch) WithDueString(s string, lang string) *ItemPatch {
+	if item.err != nil {
+		return item
+	}
+	due := struct {
+		String string `json:"string"`
+		Lang   string `json:"lang,omitempty"`
+	}{s, lang}
+	b, err := json.Marshal(due)
+	if err != nil {
+		item.err = fmt.Errorf("setting due string: %w", err)
+	} else {
+		item.attrs["due"] = string(b)
+	}
 	return item
 }
 
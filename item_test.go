@@ -69,6 +69,48 @@ func TestItemPatchHappy(t *testing.T) {
 			},
 			expected: `{"id":8,"labels":[654,"eighty"]}`,
 		},
+		{
+			id: 9,
+			setter: func(item *todoist.ItemPatch) {
+				item.WithPriority(4)
+			},
+			expected: `{"id":9,"priority":4}`,
+		},
+		{
+			id: 10,
+			setter: func(item *todoist.ItemPatch) {
+				item.WithParentID(todoist.NewTemporaryID("parent"))
+			},
+			expected: `{"id":10,"parent_id":"parent"}`,
+		},
+		{
+			id: 11,
+			setter: func(item *todoist.ItemPatch) {
+				item.WithSectionID(todoist.NewID(77))
+			},
+			expected: `{"id":11,"section_id":77}`,
+		},
+		{
+			id: 12,
+			setter: func(item *todoist.ItemPatch) {
+				item.WithDescription(`a "long" story`)
+			},
+			expected: `{"id":12,"description":"a \"long\" story"}`,
+		},
+		{
+			id: 13,
+			setter: func(item *todoist.ItemPatch) {
+				item.WithResponsibleUID(0)
+			},
+			expected: `{"id":13,"responsible_uid":null}`,
+		},
+		{
+			id: 14,
+			setter: func(item *todoist.ItemPatch) {
+				item.WithCollapsed(1)
+			},
+			expected: `{"id":14,"collapsed":1}`,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run("", func(t *testing.T) {
@@ -87,3 +129,29 @@ func TestItemPatchError(t *testing.T) {
 	assert.Nil(t, b)
 	assert.NotNil(t, err)
 }
+
+func TestPriorityNames(t *testing.T) {
+	testCases := []struct {
+		priority int
+		name     string
+	}{
+		{4, "p1"},
+		{3, "p2"},
+		{2, "p3"},
+		{1, "p4"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.name, todoist.PriorityName(tc.priority))
+			priority, err := todoist.ParsePriority(tc.name)
+			require.Nil(t, err)
+			assert.Equal(t, tc.priority, priority)
+		})
+	}
+	// Items that were never given a priority have the normal one.
+	assert.Equal(t, "p4", todoist.PriorityName(0))
+	for _, name := range []string{"", "p", "p0", "p5", "q1", "p11"} {
+		_, err := todoist.ParsePriority(name)
+		assert.NotNil(t, err, name)
+	}
+}
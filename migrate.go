@@ -0,0 +1,57 @@
+package todoist
+
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrUnsupportedVersion is returned by Load if the state was saved by a newer version of this package.
+var ErrUnsupportedVersion = errors.New("unsupported state version")
+
+// migration upgrades the client's state from one version to the next.
+type migration struct {
+	// Whether a full sync is needed after the upgrade. That's the case when properties are added to entities,
+	// because otherwise they'd only be populated for entities that change after the upgrade.
+	resync bool
+
+	// Modifies the data in place, if anything needs to change besides the version. Called with c.mu held for
+	// writing.
+	migrate func(*Client)
+}
+
+// migrations[i] upgrades the state from version i to version i+1, so the current version is len(migrations).
+// When changing clientData or the entities it contains in a way that's not compatible with the saved state,
+// append a migration.
+var migrations = []migration{
+	// Version 0 had no version property. Deleted entities were saved, and there was no queue of commands.
+	{migrate: (*Client).compact},
+	// Version 1 items lacked, e.g., priority, parent, and section.
+	{resync: true},
+	// Version 2 had no sections.
+	{resync: true},
+	// Version 3 projects lacked, e.g., parent and color.
+	{resync: true},
+	// Version 4 had no reminders.
+	{resync: true},
+	// Version 5 had no filters.
+	{resync: true},
+	// Version 6 had no user, hence no timezone for due dates.
+	{resync: true},
+}
+
+// migrate runs all migrations needed to bring the state to the current version. Must be called with c.mu held
+// for writing.
+func (c *Client) migrate() {
+	for c.data.Version < len(migrations) {
+		m := migrations[c.data.Version]
+		if m.migrate != nil {
+			m.migrate(c)
+		}
+		if m.resync {
+			c.data.SyncToken = "*"
+		}
+		c.data.Version++
+		log.WithField("version", c.data.Version).Info("Migrated state")
+	}
+}
@@ -0,0 +1,79 @@
+package todoist_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigratesUnversionedState(t *testing.T) {
+	store := todoist.NewMemoryStore()
+	require.Nil(t, store.Save([]byte(`{
+		"sync_token": "abc",
+		"items": {
+			"1": {"id": 1, "content": "kept"},
+			"2": {"id": 2, "content": "deleted", "is_deleted": 1}
+		},
+		"labels": {},
+		"notes": {"3": {"id": 3, "item_id": 2, "content": "orphan"}},
+		"project_notes": {},
+		"projects": {}
+	}`)))
+	c, err := todoist.NewClient("token", todoist.WithStore(store))
+	require.Nil(t, err)
+	require.Nil(t, c.Load())
+
+	assert.Len(t, c.SearchItems().Results(), 1)
+	assert.Empty(t, c.SearchNotes().Results())
+	require.Nil(t, c.Dump())
+	b, err := store.Load()
+	require.Nil(t, err)
+	var saved struct {
+		Version   int    `json:"version"`
+		SyncToken string `json:"sync_token"`
+	}
+	require.Nil(t, json.Unmarshal(b, &saved))
+	assert.True(t, saved.Version > 0)
+	// Some migrations require a full sync.
+	assert.Equal(t, "*", saved.SyncToken)
+}
+
+func TestLoadRejectsNewerState(t *testing.T) {
+	store := todoist.NewMemoryStore()
+	require.Nil(t, store.Save([]byte(`{"version": 1000}`)))
+	c, err := todoist.NewClient("token", todoist.WithStore(store))
+	require.Nil(t, err)
+	assert.True(t, errors.Is(c.Load(), todoist.ErrUnsupportedVersion))
+}
+
+func TestPullAfterMigrationDropsStaleEntities(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	store := todoist.NewMemoryStore()
+	// Item 99 was deleted on the servers while the client was on the old version.
+	require.Nil(t, store.Save([]byte(`{
+		"version": 1,
+		"sync_token": "abc",
+		"items": {
+			"1": {"id": 1, "project_id": 10, "content": "first"},
+			"99": {"id": 99, "project_id": 10, "content": "stale"}
+		},
+		"labels": {},
+		"notes": {},
+		"project_notes": {},
+		"projects": {"10": {"id": 10, "name": "Inbox"}}
+	}`)))
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL), todoist.WithStore(store))
+	require.Nil(t, err)
+	require.Nil(t, c.Load())
+	require.Nil(t, c.Pull())
+
+	_, ok := c.ItemByID(99)
+	assert.False(t, ok)
+	_, ok = c.ItemByID(1)
+	assert.True(t, ok)
+}
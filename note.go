@@ -15,16 +15,12 @@ type Note struct {
 	Content   string `json:"content"`
 	IsDeleted int    `json:"is_deleted"`
 	Posted    string `json:"posted"`
-
-	time time.Time
 }
 
+// Time parses the Posted property. It returns the zero time if the property is not in RFC 3339 format.
 func (note *Note) Time() time.Time {
-	if !note.time.IsZero() {
-		return note.time
-	}
-	note.time, _ = time.Parse(time.RFC3339, note.Posted)
-	return note.time
+	t, _ := time.Parse(time.RFC3339, note.Posted)
+	return t
 }
 
 type NotePatch struct {
@@ -0,0 +1,159 @@
+package todoist
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Notification is what a Notifier sends to its sink when a reminder goes off.
+type Notification struct {
+	Reminder *Reminder
+	Item     *Item
+	Time     time.Time // When the reminder was meant to go off, in the user's timezone, see Client.Location.
+}
+
+// String implements fmt.Stringer, e.g., "2020-01-02 15:04 buy milk", with the time in the user's timezone.
+func (n *Notification) String() string {
+	return fmt.Sprintf("%s %s", n.Time.Format("2006-01-02 15:04"), n.Item.Content)
+}
+
+// Sink delivers notifications to the user. See WriterSink and CommandSink for examples.
+type Sink interface {
+	Notify(*Notification) error
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(*Notification) error
+
+// Notify implements Sink.
+func (f SinkFunc) Notify(n *Notification) error {
+	return f(n)
+}
+
+// WriterSink writes a line for each notification, e.g., to os.Stdout.
+func WriterSink(w io.Writer) Sink {
+	return SinkFunc(func(n *Notification) error {
+		_, err := fmt.Fprintln(w, n)
+		return err
+	})
+}
+
+// CommandSink runs the given command for each notification, appending the item's content to the arguments, e.g.,
+// CommandSink("notify-send", "Todoist"). The item id and the time of the reminder are also passed in the
+// environment variables TODOIST_ITEM_ID and TODOIST_TIME (in RFC 3339 format).
+func CommandSink(name string, args ...string) Sink {
+	return SinkFunc(func(n *Notification) error {
+		cmd := exec.Command(name, append(append([]string(nil), args...), n.Item.Content)...)
+		cmd.Env = append(os.Environ(),
+			"TODOIST_ITEM_ID="+strconv.FormatInt(n.Item.ID, 10),
+			"TODOIST_TIME="+n.Time.Format(time.RFC3339),
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %w: %s", name, err, out)
+		}
+		return nil
+	})
+}
+
+type notifierOption func(*Notifier)
+
+// WithCheckInterval is a notifier option to set how often Run checks for reminders, one minute by default.
+func WithCheckInterval(d time.Duration) notifierOption {
+	return func(n *Notifier) {
+		n.interval = d
+	}
+}
+
+// WithLookback is a notifier option to also fire, on the first check, reminders that went off in the given period
+// before the notifier was created, e.g., while the computer was off. By default, they're not fired.
+func WithLookback(d time.Duration) notifierOption {
+	return func(n *Notifier) {
+		n.last = n.last.Add(-d)
+	}
+}
+
+// Notifier watches the client's data, which must be kept up to date by other means (e.g., calls to Pull), and
+// sends a notification to its sink for each reminder that goes off. Reminders of completed items, and location
+// reminders, are ignored.
+type Notifier struct {
+	client   *Client
+	sink     Sink
+	interval time.Duration
+
+	// Serializes checks. Reminders that go off in the period (last, now] are fired by the check at time now.
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewNotifier creates a notifier for reminders going off after its creation.
+func NewNotifier(c *Client, sink Sink, opts ...notifierOption) *Notifier {
+	n := &Notifier{
+		client:   c,
+		sink:     sink,
+		interval: time.Minute,
+		last:     time.Now(),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Run checks for reminders periodically, until the context is done.
+func (n *Notifier) Run(ctx context.Context) error {
+	t := time.NewTicker(n.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-t.C:
+			n.Check(now)
+		}
+	}
+}
+
+// Check fires the reminders that went off since the previous check (or the notifier's creation) up to the given
+// time, and returns the notifications sent. Failures of the sink are logged.
+func (n *Notifier) Check(now time.Time) []*Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !now.After(n.last) {
+		return nil
+	}
+	var due []*Notification
+	n.client.mu.RLock()
+	loc := n.client.locationLocked()
+	for _, reminder := range n.client.view.Reminders {
+		item, ok := n.client.view.Items[reminder.ItemID]
+		if !ok || item.Checked != 0 {
+			continue
+		}
+		if t, ok := reminder.Time(item, loc); ok && t.After(n.last) && !t.After(now) {
+			due = append(due, &Notification{Reminder: reminder, Item: item, Time: t})
+		}
+	}
+	n.client.mu.RUnlock()
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].Time.Before(due[j].Time)
+	})
+	n.last = now
+	for _, notification := range due {
+		if err := n.sink.Notify(notification); err != nil {
+			log.WithFields(log.Fields{
+				"reminder": notification.Reminder.ID,
+				"cause":    err,
+			}).Warning("Could not notify")
+		}
+	}
+	return due
+}
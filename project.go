@@ -2,6 +2,7 @@ package todoist
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"strconv"
 )
@@ -10,17 +11,25 @@ import (
 // only be used to parse API responses.  Use ProjectPatch for adding or updating a project (see also QueueProjectAdd,
 // QueueProjectUpdate).
 type Project struct {
-	ID         int64  `json:"id"`
-	Name       string `json:"name"`
-	ChildOrder int    `json:"child_order"`
-	IsDeleted  int    `json:"is_deleted"`
-	IsArchived int    `json:"is_archived"`
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	ParentID     int64  `json:"parent_id"` // Zero for top-level projects.
+	Color        int    `json:"color"`     // See https://developer.todoist.com/guides/#colors.
+	ChildOrder   int    `json:"child_order"`
+	Collapsed    int    `json:"collapsed"`     // Whether the sub-projects are hidden.
+	ViewStyle    string `json:"view_style"`    // Either "list" or "board".
+	Shared       bool   `json:"shared"`        // Whether the project is shared with other users.
+	InboxProject bool   `json:"inbox_project"` // Only true for the user's inbox, which can't be moved.
+	IsFavorite   int    `json:"is_favorite"`
+	IsDeleted    int    `json:"is_deleted"`
+	IsArchived   int    `json:"is_archived"`
 }
 
 // ProjectPatch holds a subset of attributes for a new or existing Todoist project, meant for an add or update command.
 type ProjectPatch struct {
 	id    int64
 	attrs map[string]string
+	err   error // If an error occurred in any of the .With* methods, see ItemPatch.
 }
 
 func NewProjectPatch(id int64) *ProjectPatch {
@@ -40,6 +49,37 @@ func (project *ProjectPatch) WithColor(value int) *ProjectPatch {
 	return project
 }
 
+// WithParentID makes the project a sub-project of the given project. It only works when adding projects; to change
+// the parent of an existing project, use QueueProjectMove. The id can be a temporary id, see ItemPatch.WithLabels.
+func (project *ProjectPatch) WithParentID(value ID) *ProjectPatch {
+	if project.err != nil {
+		return project
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		project.err = fmt.Errorf("setting parent id: %w", err)
+	} else {
+		project.attrs["parent_id"] = string(b)
+	}
+	return project
+}
+
+func (project *ProjectPatch) WithIsFavorite(value int) *ProjectPatch {
+	project.attrs["is_favorite"] = strconv.Itoa(value)
+	return project
+}
+
+// WithViewStyle sets how the apps show the project, either "list" or "board".
+func (project *ProjectPatch) WithViewStyle(value string) *ProjectPatch {
+	project.attrs["view_style"] = fmt.Sprintf("%q", value)
+	return project
+}
+
+func (project *ProjectPatch) WithCollapsed(value int) *ProjectPatch {
+	project.attrs["collapsed"] = strconv.Itoa(value)
+	return project
+}
+
 func (project *ProjectPatch) WithChildOrder(value int) *ProjectPatch {
 	project.attrs["child_order"] = strconv.Itoa(value)
 	return project
@@ -47,6 +87,9 @@ func (project *ProjectPatch) WithChildOrder(value int) *ProjectPatch {
 
 // MarshalJSON implements json.Marshaler.
 func (project *ProjectPatch) MarshalJSON() ([]byte, error) {
+	if project.err != nil {
+		return nil, project.err
+	}
 	buf := bytes.NewBuffer(nil)
 	_, _ = fmt.Fprintf(buf, `{"id":%d`, project.id)
 	for k, v := range project.attrs {
@@ -34,6 +34,27 @@ func TestProjectPatch(t *testing.T) {
 			},
 			expected: `{"id":2,"color":7}`,
 		},
+		{
+			id: 0,
+			setter: func(p *todoist.ProjectPatch) {
+				p.WithParentID(todoist.NewTemporaryID("abc"))
+			},
+			expected: `{"id":0,"parent_id":"abc"}`,
+		},
+		{
+			id: 3,
+			setter: func(p *todoist.ProjectPatch) {
+				p.WithIsFavorite(1)
+			},
+			expected: `{"id":3,"is_favorite":1}`,
+		},
+		{
+			id: 4,
+			setter: func(p *todoist.ProjectPatch) {
+				p.WithViewStyle("board")
+			},
+			expected: `{"id":4,"view_style":"board"}`,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run("", func(t *testing.T) {
@@ -45,3 +66,58 @@ func TestProjectPatch(t *testing.T) {
 		})
 	}
 }
+
+const pullWithSubProjects = `{
+	"sync_token": "t",
+	"projects": [
+		{"id": 10, "name": "Inbox", "child_order": 1, "inbox_project": true},
+		{"id": 11, "name": "Work", "child_order": 2, "color": 31, "is_favorite": 1, "view_style": "board", "shared": true},
+		{"id": 12, "name": "Meetings", "parent_id": 11, "child_order": 2},
+		{"id": 13, "name": "Reviews", "parent_id": 11, "child_order": 1},
+		{"id": 14, "name": "Minutes", "parent_id": 12, "child_order": 1}
+	]
+}`
+
+func TestProjectHierarchy(t *testing.T) {
+	ts := newStaticServer(pullWithSubProjects)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	project, ok := c.ProjectByID(11)
+	require.True(t, ok)
+	assert.Equal(t, 31, project.Color)
+	assert.Equal(t, 1, project.IsFavorite)
+	assert.Equal(t, "board", project.ViewStyle)
+	assert.True(t, project.Shared)
+	project, _ = c.ProjectByID(10)
+	assert.True(t, project.InboxProject)
+	assert.Len(t, c.SearchProjects().WithParentID(11).Results(), 2)
+
+	var ids []int64
+	var depths []int
+	for _, root := range todoist.ProjectTree(c.SearchProjects().Results()) {
+		for _, node := range root.Flatten() {
+			ids = append(ids, node.Project.ID)
+			depths = append(depths, node.Depth)
+		}
+	}
+	assert.Equal(t, []int64{10, 11, 13, 12, 14}, ids)
+	assert.Equal(t, []int{0, 0, 1, 1, 2}, depths)
+
+	c.QueueProjectMove(todoist.NewID(12), todoist.NewID(13))
+	project, _ = c.ProjectByID(12)
+	assert.Equal(t, int64(13), project.ParentID)
+	c.QueueProjectMoveToTop(todoist.NewID(12))
+	project, _ = c.ProjectByID(12)
+	assert.Equal(t, int64(0), project.ParentID)
+
+	// Sub-projects are deleted along with their parent.
+	c.QueueProjectDelete(11)
+	assert.Equal(t, 3, len(c.SearchProjects().Results()))
+	_, ok = c.ProjectByID(13)
+	assert.False(t, ok)
+	_, ok = c.ProjectByID(12)
+	assert.True(t, ok)
+}
@@ -0,0 +1,65 @@
+package todoist_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pullWithDeletedAndCompleted = `{
+	"sync_token": "t",
+	"items": [
+		{"id": 1, "project_id": 10, "content": "open"},
+		{"id": 2, "project_id": 10, "content": "deleted", "is_deleted": 1},
+		{"id": 3, "project_id": 10, "content": "completed", "checked": 1}
+	],
+	"labels": [{"id": 20, "name": "gone", "is_deleted": 1}],
+	"notes": [{"id": 30, "item_id": 2, "content": "on deleted item"}, {"id": 31, "item_id": 1, "content": "gone", "is_deleted": 1}],
+	"projects": [{"id": 10, "name": "Inbox"}, {"id": 11, "name": "Gone", "is_deleted": 1}]
+}`
+
+func newStaticServer(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, body)
+	}))
+}
+
+func TestPullPrunesDeletedEntities(t *testing.T) {
+	ts := newStaticServer(pullWithDeletedAndCompleted)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	assert.Len(t, c.SearchItems().Results(), 2)
+	_, ok := c.ItemByID(2)
+	assert.False(t, ok)
+	assert.Empty(t, c.SearchLabels().Results())
+	assert.Empty(t, c.SearchNotes().Results())
+	assert.Len(t, c.SearchProjects().Results(), 1)
+	assert.Empty(t, c.SearchArchive().Results())
+}
+
+func TestPullArchivesCompletedItems(t *testing.T) {
+	ts := newStaticServer(pullWithDeletedAndCompleted)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL), todoist.WithCompletedArchive())
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	items := c.SearchItems().Results()
+	require.Len(t, items, 1)
+	assert.Equal(t, int64(1), items[0].ID)
+	archived := c.SearchArchive().WithProjectID(10).Results()
+	require.Len(t, archived, 1)
+	assert.Equal(t, int64(3), archived[0].ID)
+
+	c.QueueItemClose(1)
+	assert.Empty(t, c.SearchItems().Results())
+	assert.Len(t, c.SearchArchive().Results(), 2)
+}
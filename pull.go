@@ -1,14 +1,11 @@
 package todoist
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 	"time"
-
-	log "github.com/sirupsen/logrus"
 )
 
 // pullResponse partially represents the JSON response from the Sync API.  I've only added what I actually need,
@@ -18,86 +15,99 @@ type pullResponse struct {
 	// entities that have changed since the previous time (according to this token) we have called the sync API.
 	SyncToken string `json:"sync_token"`
 
-	Items        []*Item    `json:"items"`
-	Labels       []*Label   `json:"labels"`
-	Notes        []*Note    `json:"notes"`
-	ProjectNotes []*Note    `json:"project_notes"`
-	Projects     []*Project `json:"projects"`
+	Items        []*Item     `json:"items"`
+	Labels       []*Label    `json:"labels"`
+	Notes        []*Note     `json:"notes"`
+	ProjectNotes []*Note     `json:"project_notes"`
+	Projects     []*Project  `json:"projects"`
+	Sections     []*Section  `json:"sections"`
+	Reminders    []*Reminder `json:"reminders"`
+	Filters      []*Filter   `json:"filters"`
+	User         *User       `json:"user"`
 }
 
 // Pull makes a sync API call to get everything that changed since the last time it was called, and updates the
-// client's in-memory data. This is used to sync back changes initiated by the client (first enqueueing commands,
-// e.g., with QueueItemAdd, and then pushing them with Push) or to sync back changes initiated by other apps (e.g.,
-// items added from a mobile phone). To reduce API calls, if this client hasn't pushed any commands since the last
-// pull, and the client already pulled once in the last minute, this method won't do anything.
+// client's in-memory data. This is mainly used to sync back changes initiated by other apps (e.g., items added
+// from a mobile phone), as changes initiated by the client (enqueueing commands, e.g., with QueueItemAdd, and then
+// pushing them with Push) are applied locally. To reduce API calls, if the client already pulled once in the last
+// minute, this method won't do anything.
 func (c *Client) Pull() error {
-	// Avoid pulling too often. The timestamp is set by this method on successful update, but can be set by
-	// the push method too in order to signal that we need to pull the changes down.
-	if time.Since(c.lastPulled) <= time.Minute {
+	return c.PullContext(context.Background())
+}
+
+// PullContext is like Pull, but the remote call is bound to the given context.
+func (c *Client) PullContext(ctx context.Context) error {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	c.mu.RLock()
+	lastPulled, syncToken := c.lastPulled, c.data.SyncToken
+	c.mu.RUnlock()
+	// Avoid pulling too often. The timestamp is set by this method on successful update.
+	if time.Since(lastPulled) <= time.Minute {
 		return nil
 	}
+	pr, err := c.fetch(ctx, syncToken)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if syncToken == "*" {
+		// A full sync, e.g., after a migration: entities deleted meanwhile are not in the response.
+		c.replaceData(pr)
+	} else {
+		c.incorporate(pr)
+		c.refreshView()
+	}
+	c.lastPulled = time.Now()
+	return nil
+}
+
+// fetch makes the sync API call to get everything that changed since the given sync token.
+func (c *Client) fetch(ctx context.Context, syncToken string) (*pullResponse, error) {
 	data := make(url.Values)
 	data.Set("token", c.token)
-	data.Set("sync_token", c.data.SyncToken)
-	data.Set("resource_types", `["items","labels","notes","project_notes","projects"]`)
-	r, err := http.PostForm(c.endpoint, data)
+	data.Set("sync_token", syncToken)
+	data.Set("resource_types", `["items","labels","notes","project_notes","projects","sections","reminders","filters","user"]`)
+	b, err := c.post(ctx, "pull", c.endpoint, data)
 	if err != nil {
-		return fmt.Errorf("pull: %w", err)
+		return nil, err
+	}
+	var pr *pullResponse
+	if err := json.Unmarshal(b, &pr); err != nil {
+		return nil, fmt.Errorf("pull, unmarshal: %w", err)
 	}
-	defer func() {
-		if err := r.Body.Close(); err != nil {
-			log.WithFields(log.Fields{
-				"op":    "pull",
-				"cause": err,
-			}).Warning("Could not close request body")
-		}
-	}()
-	switch r.StatusCode {
-	case http.StatusOK:
-		var pr *pullResponse
-		b, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			return fmt.Errorf("pull, read body: %w", err)
-		}
-		_, _ = c.wlog.Write([]byte(`{"type": "response", "response": `))
-		_, _ = c.wlog.Write(b)
-		_, _ = c.wlog.Write([]byte("}\n"))
-		err = json.Unmarshal(b, &pr)
-		if err != nil {
-			return fmt.Errorf("pull, unmarshal: %w", err)
-		}
-		c.data.SyncToken = pr.SyncToken
+	return pr, nil
+}
 
-		for _, item := range pr.Items {
-			c.updateItem(item)
-		}
-		for _, project := range pr.Projects {
-			c.updateProject(project)
-		}
-		for _, label := range pr.Labels {
-			c.updateLabel(label)
-		}
-		for _, note := range pr.Notes {
-			c.updateNote(note)
-		}
-		for _, note := range pr.ProjectNotes {
-			c.updateNote(note)
-		}
-		c.lastPulled = time.Now()
-		return nil
-	default:
-		var responseText string
-		b, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			responseText = fmt.Sprintf("unknown, because of error: %v", err)
-		} else {
-			responseText = string(b)
-		}
-		log.WithFields(log.Fields{
-			"op":   "pull",
-			"code": r.StatusCode,
-			"text": responseText,
-		}).Error("Unhandled response")
-		return fmt.Errorf("%d: %w", r.StatusCode, ErrStatusCode)
+// incorporate updates the client's data with the response to a sync API call. Must be called with c.mu held for
+// writing, and followed by a call to refreshView.
+func (c *Client) incorporate(pr *pullResponse) {
+	c.data.SyncToken = pr.SyncToken
+	c.updateUser(pr.User)
+	for _, item := range pr.Items {
+		c.updateItem(item)
+	}
+	for _, project := range pr.Projects {
+		c.updateProject(project)
+	}
+	for _, section := range pr.Sections {
+		c.updateSection(section)
+	}
+	for _, reminder := range pr.Reminders {
+		c.updateReminder(reminder)
+	}
+	for _, filter := range pr.Filters {
+		c.updateFilter(filter)
+	}
+	for _, label := range pr.Labels {
+		c.updateLabel(label)
+	}
+	for _, note := range pr.Notes {
+		c.updateNote(note)
+	}
+	for _, note := range pr.ProjectNotes {
+		c.updateNote(note)
 	}
+	c.data.dropDanglingNotes()
 }
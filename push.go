@@ -2,15 +2,11 @@ package todoist
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"net/url"
-	"time"
-
-	log "github.com/sirupsen/logrus"
 )
 
 // Error is one of the two possible responses for a Todoist command.
@@ -29,15 +25,6 @@ type commandStatus struct {
 	err *Error
 }
 
-func (status commandStatus) Err() error {
-	// If this looks odd:
-	// https://golang.org/doc/faq#nil_error
-	if status.err != nil {
-		return status.err
-	}
-	return nil
-}
-
 // The byte slice is either a string "ok", in case of a successful command, or another complex type to represent
 // the command error.
 func (status *commandStatus) UnmarshalJSON(b []byte) error {
@@ -49,7 +36,8 @@ func (status *commandStatus) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// ErrStatusCode is returned in case the response from the API contains a status code that the client can't handle.
+// ErrStatusCode is matched by all errors due to a response from the API with a status code other than 200. See
+// StatusError for a finer classification.
 var ErrStatusCode = errors.New("unhandled status code")
 
 type pushResponse struct {
@@ -60,32 +48,75 @@ type pushResponse struct {
 	TempIDMapping map[string]int64 `json:"temp_id_mapping"`
 }
 
-func (r *pushResponse) Err() error {
+// FailedCommand describes a queued command that the servers rejected.
+type FailedCommand struct {
+	UUID string          // Identifies the command, e.g., for RetryCommands and DropCommands.
+	Type string          // E.g., "item_update".
+	Args json.RawMessage // The arguments as sent to the servers, e.g., {"id":42,"content":"foo"}.
+	Err  *Error          // The error code and message returned by the servers.
+}
+
+// PushError is returned by Push when the servers reject one or more commands. The rejected commands stay in the
+// queue, but they won't be sent again and their changes are not visible in the client's data until RetryCommands
+// is called. Use DropCommands to discard them instead.
+type PushError struct {
+	Failed []*FailedCommand
+}
+
+// Error implements error.
+func (e *PushError) Error() string {
 	var b bytes.Buffer
-	for command, status := range r.SyncStatus {
-		if err := status.Err(); err != nil {
-			fmt.Fprintf(&b, "%v: %v\n", command, err)
-		}
+	_, _ = fmt.Fprintf(&b, "%d failed command(s)", len(e.Failed))
+	for _, f := range e.Failed {
+		_, _ = fmt.Fprintf(&b, "; %s %s %s: %v", f.UUID, f.Type, f.Args, f.Err)
 	}
-	if b.Len() > 0 {
-		return errors.New(b.String())
+	return b.String()
+}
+
+func newFailedCommand(cmd *command) *FailedCommand {
+	f := &FailedCommand{
+		UUID: cmd.UUID,
+		Type: cmd.Type,
+		Err:  cmd.Err,
 	}
-	return nil
+	if raw, ok := cmd.Args.(json.RawMessage); ok {
+		f.Args = raw
+	} else if b, err := json.Marshal(cmd.Args); err == nil {
+		f.Args = b
+	}
+	return f
 }
 
-// Push flushes all queued commands. It will return an error if any of them is not successful. If more than one
-// command returns an error, those errors will all be reported as one. Other than the temporary to permanent id
-// mapping (see PermanentID), internal state is not updated after the push, so one should call Pull for that.
+// Push flushes all queued commands, except those that previously failed (see PushError). If any of them is not
+// successful, it will return a *PushError describing the failures. Failed remote calls are retried according to
+// the client's RetryPolicy, which is safe because the servers won't execute the same command twice.
 //
-// Note for possible future changes. We could avoid pulling back our changes in principle, by already doing the
-// changes in the client's in-memory data using the temporary IDs, and only updating such ids after the push,
-// but that would require more implementation work in the client. Since one still has to call Pull periodically to
-// incorporate changes done in other clients (e.g., mobile phone) all the same, I'm sticking with pull-after-push
-// for now.
+// Queued commands are applied to the client's in-memory data as soon as they're queued, using temporary ids for
+// new entities (see QueueItemAdd). When the servers respond, successful commands are applied again with the
+// permanent ids they assigned and removed from the queue, while the local changes of unsuccessful commands are
+// rolled back.
+// Properties that are computed by the servers (e.g., the human-readable due date string) are only updated by
+// the next Pull.
 func (c *Client) Push() error {
+	return c.PushContext(context.Background())
+}
+
+// PushContext is like Push, but the remote call is bound to the given context.
+func (c *Client) PushContext(ctx context.Context) error {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	// Commands queued while the request is in flight will be sent with the next push.
+	c.mu.RLock()
+	var commands []*command
+	for _, cmd := range c.data.Commands {
+		if cmd.Err == nil {
+			commands = append(commands, cmd)
+		}
+	}
+	c.mu.RUnlock()
 	data := make(url.Values)
 	data.Set("token", c.token)
-	b, err := json.Marshal(c.commands)
+	b, err := json.Marshal(commands)
 	if err != nil {
 		return err
 	}
@@ -93,56 +124,103 @@ func (c *Client) Push() error {
 	_, _ = c.wlog.Write(b)
 	_, _ = c.wlog.Write([]byte("}\n"))
 	data.Set("commands", string(b))
-	r, err := http.PostForm(c.endpoint, data)
+	b, err = c.post(ctx, "push", c.endpoint, data)
 	if err != nil {
-		return fmt.Errorf("push: %w", err)
-	}
-	defer func() {
-		if err := r.Body.Close(); err != nil {
-			log.WithFields(log.Fields{
-				"op":    "push",
-				"cause": err,
-			}).Warning("Could not close request body")
-		}
-	}()
-	switch r.StatusCode {
-	case http.StatusOK:
-		b, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			return nil
+		return err
+	}
+	var pr pushResponse
+	if err := json.Unmarshal(b, &pr); err != nil {
+		return fmt.Errorf("push, unmarshal: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for tid, pid := range pr.TempIDMapping {
+		c.t2p[tid] = pid
+	}
+	sent := make(map[*command]bool, len(commands))
+	for _, cmd := range commands {
+		sent[cmd] = true
+	}
+	var queue []*command
+	var pushErr PushError
+	for _, cmd := range c.data.Commands {
+		status, ok := pr.SyncStatus[cmd.UUID]
+		switch {
+		case !sent[cmd] || !ok:
+			// Either queued during the push, or we don't know what happened to it; keep it for the next push.
+			queue = append(queue, cmd)
+		case status.err != nil:
+			// Commands are never modified in place, see Client.mu.
+			failed := *cmd
+			failed.Err = status.err
+			queue = append(queue, &failed)
+			pushErr.Failed = append(pushErr.Failed, newFailedCommand(&failed))
+		default:
+			c.applyLogged(c.data, cmd)
 		}
-		_, _ = c.wlog.Write([]byte(`{"type": "response", "response": `))
-		_, _ = c.wlog.Write(b)
-		_, _ = c.wlog.Write([]byte("}\n"))
-		var pr pushResponse
-		err = json.Unmarshal(b, &pr)
-		if err != nil {
-			return fmt.Errorf("push, unmarshal: %w", err)
+	}
+	c.data.Commands = queue
+	c.forgetLocalIDs()
+	c.refreshView()
+	if len(pushErr.Failed) > 0 {
+		return &pushErr
+	}
+	return nil
+}
+
+// FailedCommands lists the commands that the servers rejected, and that were neither retried nor dropped.
+func (c *Client) FailedCommands() []*FailedCommand {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var failed []*FailedCommand
+	for _, cmd := range c.data.Commands {
+		if cmd.Err != nil {
+			failed = append(failed, newFailedCommand(cmd))
 		}
-		if err := pr.Err(); err != nil {
-			return err
+	}
+	return failed
+}
+
+// RetryCommands marks the failed commands with the given UUIDs (all failed commands, if none are given) to be sent
+// again with the next push, and applies them to the client's data again. Commands are given new UUIDs, otherwise
+// the servers would not execute them again.
+func (c *Client) RetryCommands(uuids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, cmd := range c.data.Commands {
+		if cmd.Err != nil && matchesAny(cmd.UUID, uuids) {
+			retry := newCommand(cmd.Type, cmd.Args)
+			retry.TempID = cmd.TempID
+			c.data.Commands[i] = retry
 		}
-		for tid, pid := range pr.TempIDMapping {
-			c.t2p[tid] = pid
+	}
+	c.refreshView()
+}
+
+// DropCommands removes from the queue the failed commands with the given UUIDs (all failed commands, if none are
+// given).
+func (c *Client) DropCommands(uuids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var queue []*command
+	for _, cmd := range c.data.Commands {
+		if cmd.Err == nil || !matchesAny(cmd.UUID, uuids) {
+			queue = append(queue, cmd)
 		}
-		c.commands = nil
-		c.lastPulled = time.Time{}
-		return nil
-	default:
-		var responseText string
-		b, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			responseText = fmt.Sprintf("unknown, because of error reading body: %v", err)
-		} else {
-			responseText = string(b)
+	}
+	c.data.Commands = queue
+	c.refreshView()
+}
+
+// matchesAny reports whether the value is among the candidates, or if there are no candidates.
+func matchesAny(value string, candidates []string) bool {
+	if len(candidates) == 0 {
+		return true
+	}
+	for _, candidate := range candidates {
+		if value == candidate {
+			return true
 		}
-		// This log line should be superfluous, because the caller should handle the error.
-		// Possibly logging; only the outermost layer should log.
-		log.WithFields(log.Fields{
-			"op":   "push",
-			"code": r.StatusCode,
-			"text": responseText,
-		}).Error("Unhandled response status code")
-		return fmt.Errorf("%d: %w", r.StatusCode, ErrStatusCode)
 	}
+	return false
 }
@@ -0,0 +1,53 @@
+package todoist_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushErrorListsFailedCommands(t *testing.T) {
+	fs := newFakeServer()
+	fs.failTypes = map[string]bool{"item_update": true}
+	defer fs.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	c.QueueItemUpdate(todoist.NewItemPatch(1).WithContent("edited"))
+	c.QueueItemClose(2)
+	err = c.Push()
+	var pushErr *todoist.PushError
+	require.True(t, errors.As(err, &pushErr))
+	require.Len(t, pushErr.Failed, 1)
+	failed := pushErr.Failed[0]
+	assert.Equal(t, "item_update", failed.Type)
+	assert.JSONEq(t, `{"id":1,"content":"edited"}`, string(failed.Args))
+	assert.Equal(t, 20, failed.Err.Code)
+	assert.Equal(t, "Invalid command", failed.Err.Message)
+	assert.Equal(t, pushErr.Failed, c.FailedCommands())
+	assert.Equal(t, int64(2), atomic.LoadInt64(&fs.commands))
+
+	// Failed commands are not sent again...
+	require.Nil(t, c.Push())
+	assert.Equal(t, int64(2), atomic.LoadInt64(&fs.commands))
+
+	// ...unless retried, in which case their changes are visible again until the push.
+	c.RetryCommands()
+	item, _ := c.ItemByID(1)
+	assert.Equal(t, "edited", item.Content)
+	err = c.Push()
+	require.True(t, errors.As(err, &pushErr))
+	assert.Equal(t, int64(3), atomic.LoadInt64(&fs.commands))
+	require.Len(t, c.FailedCommands(), 1)
+	assert.NotEqual(t, failed.UUID, c.FailedCommands()[0].UUID)
+
+	c.DropCommands(c.FailedCommands()[0].UUID)
+	assert.Empty(t, c.FailedCommands())
+	item, _ = c.ItemByID(1)
+	assert.Equal(t, "first", item.Content)
+}
@@ -0,0 +1,446 @@
+package todoist
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a parsed filter query, in the syntax of Todoist filters (see
+// https://get.todoist.help/hc/en-us/articles/205248842), e.g., "(today | overdue) & #Work & !@waiting". Use
+// ParseQuery to create one and ItemScan.WithQuery to look for the items it matches.
+//
+// Conditions are combined with & (and), | (or), ! (not), and parentheses; & binds tighter than |. The supported
+// conditions are:
+//
+//	today, tomorrow, overdue     due dates
+//	no date                      items without a due date
+//	7 days, next 7 days          due in the next 7 days, today included
+//	p1, p2, p3, p4               priorities, p1 being the most urgent
+//	no priority                  same as p4
+//	@name                        items with the label
+//	no labels                    items without labels
+//	#name                        items in the project
+//	##name                       items in the project or in its sub-projects
+//	/name                        items in the section
+//	assigned                     items assigned to someone
+//	assigned to: me              items assigned to the user, see Client.User
+//	assigned to: others          items assigned to other users
+//	assigned to: 1234            items assigned to the user with the given id
+//	search: text                 items whose content contains the text
+//
+// Names are case-insensitive, and * matches any sequence of characters, e.g., "#Work*" or "/*". Any other text
+// is treated as in search conditions. A backslash escapes the character after it, e.g., "search: R\&D".
+//
+// Conditions starting with - or containing :, other than search and assigned to conditions, are rejected: they
+// meant negation and conjunction in the syntax of earlier versions, e.g., "@bug:-@maybe" for "@bug & !@maybe".
+type Query struct {
+	text string
+	root queryNode
+}
+
+// String returns the query as it was given to ParseQuery.
+func (q *Query) String() string {
+	return q.text
+}
+
+// QuerySyntaxError is returned by ParseQuery for malformed queries.
+type QuerySyntaxError struct {
+	Query string
+	Pos   int // Byte offset in the query where the error was detected.
+	Msg   string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("query %q: position %d: %s", e.Query, e.Pos, e.Msg)
+}
+
+type queryNode interface {
+	compile(*queryContext) itemPredicate
+}
+
+type queryAnd struct {
+	left, right queryNode
+}
+
+type queryOr struct {
+	left, right queryNode
+}
+
+type queryNot struct {
+	operand queryNode
+}
+
+type queryTerm struct {
+	text string // Unescaped and trimmed.
+}
+
+// ParseQuery parses a filter query. Names of labels, projects, and sections are resolved when the query is used,
+// see ItemScan.WithQuery.
+func ParseQuery(text string) (*Query, error) {
+	p := &queryParser{text: text}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		return nil, p.errorf("unexpected %q", p.text[p.pos])
+	}
+	return &Query{text: text, root: root}, nil
+}
+
+type queryParser struct {
+	text string
+	pos  int
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return &QuerySyntaxError{Query: p.text, Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) skipSpace() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// accept consumes the given operator, if it's next.
+func (p *queryParser) accept(op byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) or() (queryNode, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept('|') {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &queryOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) and() (queryNode, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept('&') {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &queryAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) unary() (queryNode, error) {
+	if p.accept('!') {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &queryNot{operand: operand}, nil
+	}
+	if p.accept('(') {
+		node, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(')') {
+			if p.pos < len(p.text) {
+				return nil, p.errorf("expected ) but found %q", p.text[p.pos])
+			}
+			return nil, p.errorf("missing )")
+		}
+		return node, nil
+	}
+	return p.term()
+}
+
+// term reads a condition, up to the next unescaped operator or parenthesis.
+func (p *queryParser) term() (queryNode, error) {
+	p.skipSpace()
+	start := p.pos
+	// Position of the first unescaped colon, to tell the old search syntax, see below.
+	colon := -1
+	var b strings.Builder
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		if c == '&' || c == '|' || c == '(' || c == ')' {
+			break
+		}
+		if c == ':' && colon < 0 {
+			colon = p.pos
+		}
+		if c == '\\' {
+			p.pos++
+			if p.pos == len(p.text) {
+				return nil, p.errorf("nothing to escape")
+			}
+			c = p.text[p.pos]
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	text := strings.TrimSpace(b.String())
+	if text == "" {
+		if p.pos < len(p.text) {
+			return nil, p.errorf("expected condition but found %q", p.text[p.pos])
+		}
+		return nil, p.errorf("expected condition")
+	}
+	// The search windows used to take conditions combined with colons and negated with a minus sign, e.g.,
+	// "@bug:-@maybe". Rather than silently matching something else, point to the new syntax.
+	isPrefixed := hasPrefixFold(text, "search:") || hasPrefixFold(text, "assigned to:")
+	switch {
+	case p.text[start] == '-':
+		return nil, &QuerySyntaxError{Query: p.text, Pos: start,
+			Msg: `use ! rather than - to negate a condition, or \- to search for text starting with -`}
+	case colon >= 0 && !isPrefixed:
+		return nil, &QuerySyntaxError{Query: p.text, Pos: colon,
+			Msg: `use & rather than : to combine conditions, or \: to search for text containing :`}
+	case text == "#" || text == "##" || text == "@" || text == "/":
+		return nil, &QuerySyntaxError{Query: p.text, Pos: start, Msg: fmt.Sprintf("missing name after %q", text)}
+	case hasPrefixFold(text, "search:") && strings.TrimSpace(text[len("search:"):]) == "":
+		return nil, &QuerySyntaxError{Query: p.text, Pos: start, Msg: "missing text after search:"}
+	case hasPrefixFold(text, "assigned to:") && strings.TrimSpace(text[len("assigned to:"):]) == "":
+		return nil, &QuerySyntaxError{Query: p.text, Pos: start, Msg: "missing user after assigned to:"}
+	}
+	return &queryTerm{text: text}, nil
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+func (n *queryAnd) compile(ctx *queryContext) itemPredicate {
+	left, right := n.left.compile(ctx), n.right.compile(ctx)
+	return func(item *Item) bool {
+		return left(item) && right(item)
+	}
+}
+
+func (n *queryOr) compile(ctx *queryContext) itemPredicate {
+	left, right := n.left.compile(ctx), n.right.compile(ctx)
+	return func(item *Item) bool {
+		return left(item) || right(item)
+	}
+}
+
+func (n *queryNot) compile(ctx *queryContext) itemPredicate {
+	return negate(n.operand.compile(ctx))
+}
+
+// queryContext has what's needed to resolve names in conditions. The data must not change during compilation.
+type queryContext struct {
+	data  *clientData
+	now   time.Time
+	today time.Time // Midnight of the day of now, in the same location.
+}
+
+func (n *queryTerm) compile(ctx *queryContext) itemPredicate {
+	text := n.text
+	lower := strings.ToLower(text)
+	if priority, err := ParsePriority(lower); err == nil {
+		return func(item *Item) bool {
+			return item.Priority == priority
+		}
+	}
+	switch lower {
+	case "today":
+		return ctx.dueBetween(ctx.today, ctx.today.AddDate(0, 0, 1))
+	case "tomorrow":
+		return ctx.dueBetween(ctx.today.AddDate(0, 0, 1), ctx.today.AddDate(0, 0, 2))
+	case "overdue", "od":
+		return func(item *Item) bool {
+			if item.Due == nil || item.Due.Date == "" {
+				return false
+			}
+			if !item.Due.IsAllDay() {
+				return item.Due.TimeIn(ctx.today.Location()).Before(ctx.now)
+			}
+			return item.Due.Day(ctx.today.Location()).Before(ctx.today)
+		}
+	case "no date":
+		return func(item *Item) bool {
+			return item.Due == nil
+		}
+	case "no labels":
+		return func(item *Item) bool {
+			return len(item.Labels) == 0
+		}
+	case "no priority":
+		return func(item *Item) bool {
+			return item.Priority == 1
+		}
+	case "assigned":
+		return func(item *Item) bool {
+			return item.ResponsibleUID != 0
+		}
+	}
+	if days, ok := parseDays(lower); ok {
+		return ctx.dueBetween(ctx.today, ctx.today.AddDate(0, 0, days))
+	}
+	switch {
+	case strings.HasPrefix(text, "##"):
+		ids := ctx.projectIDs(text[2:])
+		for id := range ids {
+			ctx.addSubProjects(id, ids)
+		}
+		return func(item *Item) bool {
+			return ids[item.ProjectID]
+		}
+	case strings.HasPrefix(text, "#"):
+		ids := ctx.projectIDs(text[1:])
+		return func(item *Item) bool {
+			return ids[item.ProjectID]
+		}
+	case strings.HasPrefix(text, "@"):
+		ids := make(map[int64]bool)
+		for _, label := range ctx.data.Labels {
+			if matchName(text[1:], label.Name) {
+				ids[label.ID] = true
+			}
+		}
+		return func(item *Item) bool {
+			for _, id := range item.Labels {
+				if ids[id] {
+					return true
+				}
+			}
+			return false
+		}
+	case strings.HasPrefix(text, "/"):
+		ids := make(map[int64]bool)
+		for _, section := range ctx.data.Sections {
+			if matchName(text[1:], section.Name) {
+				ids[section.ID] = true
+			}
+		}
+		return func(item *Item) bool {
+			return ids[item.SectionID]
+		}
+	case hasPrefixFold(text, "assigned to:"):
+		who := strings.TrimSpace(text[len("assigned to:"):])
+		var me int64
+		if ctx.data.User != nil {
+			me = ctx.data.User.ID
+		}
+		switch strings.ToLower(who) {
+		case "me":
+			return func(item *Item) bool {
+				return me != 0 && item.ResponsibleUID == me
+			}
+		case "others":
+			return func(item *Item) bool {
+				return item.ResponsibleUID != 0 && item.ResponsibleUID != me
+			}
+		}
+		// Collaborators are not synced, so only user ids can be resolved.
+		uid, err := strconv.ParseInt(who, 10, 64)
+		return func(item *Item) bool {
+			return err == nil && item.ResponsibleUID == uid
+		}
+	case hasPrefixFold(text, "search:"):
+		lower = strings.TrimSpace(lower[len("search:"):])
+	}
+	return func(item *Item) bool {
+		return strings.Contains(strings.ToLower(item.Content), lower)
+	}
+}
+
+// parseDays parses conditions such as "7 days" or "next 7 days".
+func parseDays(s string) (int, bool) {
+	s = strings.TrimPrefix(s, "next ")
+	fields := strings.Fields(s)
+	if len(fields) != 2 || (fields[1] != "days" && fields[1] != "day") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(fields[0])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// dueBetween matches items due on days in the interval [from, to).
+func (ctx *queryContext) dueBetween(from, to time.Time) itemPredicate {
+	return func(item *Item) bool {
+		if item.Due == nil || item.Due.Date == "" {
+			return false
+		}
+		day := item.Due.Day(ctx.today.Location())
+		return !day.Before(from) && day.Before(to)
+	}
+}
+
+func (ctx *queryContext) projectIDs(pattern string) map[int64]bool {
+	ids := make(map[int64]bool)
+	for _, project := range ctx.data.Projects {
+		if matchName(pattern, project.Name) {
+			ids[project.ID] = true
+		}
+	}
+	return ids
+}
+
+func (ctx *queryContext) addSubProjects(parent int64, ids map[int64]bool) {
+	for _, project := range ctx.data.Projects {
+		if project.ParentID == parent && !ids[project.ID] {
+			ids[project.ID] = true
+			ctx.addSubProjects(project.ID, ids)
+		}
+	}
+}
+
+// matchName reports whether the name matches the pattern, ignoring case. A * in the pattern matches any sequence
+// of characters.
+func matchName(pattern, name string) bool {
+	pattern, name = strings.ToLower(strings.TrimSpace(pattern)), strings.ToLower(name)
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == name
+	}
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+	return strings.HasSuffix(name, last)
+}
+
+// WithQuery looks for items matching the query. Relative dates such as "today" are relative to the given time, in
+// the user's timezone (see Client.Location). Names in the query are resolved against the current data.
+func (s *ItemScan) WithQuery(q *Query, now time.Time) *ItemScan {
+	s.client.mu.RLock()
+	now = now.In(s.client.locationLocked())
+	ctx := &queryContext{
+		data:  s.client.view,
+		now:   now,
+		today: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+	}
+	predicate := q.root.compile(ctx)
+	s.client.mu.RUnlock()
+	s.predicates = append(s.predicates, predicate)
+	return s
+}
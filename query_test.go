@@ -0,0 +1,117 @@
+package todoist_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pullForQueries = `{
+	"sync_token": "t",
+	"items": [
+		{"id": 1, "project_id": 10, "content": "Call mom", "priority": 4, "labels": [100], "due": {"date": "2020-01-02"}},
+		{"id": 2, "project_id": 11, "section_id": 40, "content": "Write report", "priority": 3, "responsible_uid": 7,
+			"due": {"date": "2020-01-01"}},
+		{"id": 3, "project_id": 12, "content": "R&D budget", "priority": 1, "labels": [101],
+			"due": {"date": "2020-01-05T09:00:00Z"}},
+		{"id": 4, "project_id": 10, "content": "Read book", "priority": 1},
+		{"id": 5, "project_id": 11, "content": "Meeting", "priority": 2, "due": {"date": "2020-01-02T08:00:00Z"}}
+	],
+	"projects": [
+		{"id": 10, "name": "Inbox"},
+		{"id": 11, "name": "Work"},
+		{"id": 12, "name": "Budget", "parent_id": 11}
+	],
+	"sections": [{"id": 40, "project_id": 11, "name": "Reports"}],
+	"labels": [{"id": 100, "name": "family"}, {"id": 101, "name": "waiting"}],
+	"user": {"id": 7, "full_name": "Jane", "tz_info": {"timezone": "UTC"}}
+}`
+
+func TestQuery(t *testing.T) {
+	ts := newStaticServer(pullForQueries)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+	now := time.Date(2020, time.January, 2, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		query    string
+		expected []int64
+	}{
+		{"today", []int64{1, 5}},
+		{"overdue", []int64{2, 5}},
+		{"no date", []int64{4}},
+		{"3 days", []int64{1, 5}},
+		{"next 4 days", []int64{1, 3, 5}},
+		{"p1", []int64{1}},
+		{"p1 | p2", []int64{1, 2}},
+		{"P4 & no date", []int64{4}},
+		{"no priority", []int64{3, 4}},
+		{"@family", []int64{1}},
+		{"@WAIT*", []int64{3}},
+		{"no labels", []int64{2, 4, 5}},
+		{"#Work", []int64{2, 5}},
+		{"##Work", []int64{2, 3, 5}},
+		{"##work & !#work", []int64{3}},
+		{"#*", []int64{1, 2, 3, 4, 5}},
+		{"/Reports", []int64{2}},
+		{"!/*", []int64{1, 3, 4, 5}},
+		{"assigned", []int64{2}},
+		{"assigned to: 7", []int64{2}},
+		{"assigned to: 8", nil},
+		{"assigned to: me", []int64{2}},
+		{"assigned to: others", nil},
+		{"search: r\\&d", []int64{3}},
+		{"meeting\\: notes | \\-read", nil},
+		{"read", []int64{4}},
+		{"!(today | overdue) & !no date", []int64{3}},
+		{"(p1 | p2) & overdue", []int64{2}},
+		{"p2 | p1 & no date", []int64{2}},
+		{"!!p1", []int64{1}},
+		{"#Nowhere", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			q, err := todoist.ParseQuery(tc.query)
+			require.Nil(t, err)
+			ids := itemIDs(c.SearchItems().WithQuery(q, now).Results())
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestQuerySyntaxErrors(t *testing.T) {
+	testCases := []struct {
+		query string
+		pos   int
+	}{
+		{"", 0},
+		{"p1 &", 4},
+		{"p1 & (today", 11},
+		{"p1 )", 3},
+		{"(p1 | ) & today", 6},
+		{"today & #", 8},
+		{"search: ", 0},
+		{"!", 1},
+		{"p1 \\", 4},
+		// The old syntax of the search windows.
+		{"@bug:-@maybe", 4},
+		{"p1 & -@maybe", 5},
+		{"!@bug:foo", 5},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			_, err := todoist.ParseQuery(tc.query)
+			var syntaxErr *todoist.QuerySyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "%v", err)
+			assert.Equal(t, tc.pos, syntaxErr.Pos)
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package todoist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence is a parsed recurring due string, see ParseRecurrence.
+type Recurrence struct {
+	// FromCompletion is set for due strings starting with "every!", whose occurrences count from the time the item
+	// is completed, rather than from the previous occurrence.
+	FromCompletion bool
+
+	kind     recurrenceKind
+	interval int          // For recurInterval, the number of units between occurrences.
+	unit     string       // For recurInterval, one of day, week, month, and year.
+	weekdays [7]bool      // For recurWeekdays.
+	ordinal  int          // For recurOrdinalWeekday, from 1 to 5, or -1 for the last one in the month.
+	weekday  time.Weekday // For recurOrdinalWeekday.
+	month    time.Month   // For recurYearDay.
+	day      int          // For recurMonthDay and recurYearDay, or -1 for the last day of the month.
+
+	// For monthly and yearly intervals, the day of the month of the first occurrence, which later occurrences keep
+	// to as far as their month allows. Zero until First or Advance is called.
+	anchor int
+
+	// The time of the day of the occurrences, if given in the due string.
+	timed        bool
+	hour, minute int
+}
+
+type recurrenceKind int
+
+const (
+	recurInterval       recurrenceKind = iota // every 3 days
+	recurWeekdays                             // every monday, friday
+	recurOrdinalWeekday                       // every 3rd friday
+	recurMonthDay                             // every 15th
+	recurYearDay                              // every jan 27
+)
+
+// errNotRecurring is returned by the recurrence methods of Due for due dates that don't recur.
+var errNotRecurring = errors.New("not recurring")
+
+// ParseRecurrence parses a recurring due string in English (see
+// https://get.todoist.help/hc/en-us/articles/360000636289), e.g.:
+//
+//	every day, daily, every other day, every 3 days     also weeks, months, and years
+//	every weekday, every workday, every weekend
+//	every monday, every mon, fri                        one or more days of the week
+//	every 3rd friday, every last monday                 a day of the week in each month
+//	every 15th, every last day                          a day of each month
+//	every jan 27, every 27 january                      a day of each year
+//	every! 3 days                                       counting from the completion, for all of the above
+//
+// Any of the above may be followed or preceded by a time, e.g., "every monday 9am" or "at 18:30 every day".
+func ParseRecurrence(s string) (*Recurrence, error) {
+	words, hour, minute, timed, ok := dueWords(s)
+	if ok {
+		if r, ok := parseRecurrence(words); ok {
+			r.timed, r.hour, r.minute = timed, hour, minute
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("recurrence %q: not understood", s)
+}
+
+// isRecurring tells whether the first word of a due string makes it recurring.
+func isRecurring(word string) bool {
+	switch word {
+	case "every", "every!", "daily", "weekly", "monthly", "yearly":
+		return true
+	}
+	return false
+}
+
+func parseRecurrence(words []string) (*Recurrence, bool) {
+	r := &Recurrence{kind: recurInterval, interval: 1}
+	if len(words) == 1 {
+		switch words[0] {
+		case "daily":
+			r.unit = "day"
+		case "weekly":
+			r.unit = "week"
+		case "monthly":
+			r.unit = "month"
+		case "yearly":
+			r.unit = "year"
+		default:
+			return nil, false
+		}
+		return r, true
+	}
+	if len(words) < 2 || (words[0] != "every" && words[0] != "every!") {
+		return nil, false
+	}
+	r.FromCompletion = words[0] == "every!"
+	words = words[1:]
+	switch len(words) {
+	case 1:
+		switch w := words[0]; w {
+		case "day", "week", "month", "year":
+			r.unit = w
+			return r, true
+		case "weekday", "workday":
+			r.kind = recurWeekdays
+			for d := time.Monday; d <= time.Friday; d++ {
+				r.weekdays[d] = true
+			}
+			return r, true
+		case "weekend":
+			r.kind = recurWeekdays
+			r.weekdays[time.Saturday], r.weekdays[time.Sunday] = true, true
+			return r, true
+		}
+		if day, ok := parseMonthDayNumber(words[0]); ok {
+			r.kind, r.day = recurMonthDay, day
+			return r, true
+		}
+	case 2:
+		if words[0] == "last" && words[1] == "day" {
+			r.kind, r.day = recurMonthDay, -1
+			return r, true
+		}
+		if unit := strings.TrimSuffix(words[1], "s"); unit == "day" || unit == "week" || unit == "month" || unit == "year" {
+			r.unit = unit
+			if words[0] == "other" {
+				r.interval = 2
+				return r, true
+			}
+			n, err := strconv.Atoi(words[0])
+			r.interval = n
+			return r, err == nil && n > 0
+		}
+		if ordinal, ok := parseOrdinal(words[0]); ok {
+			if wd, ok := parseWeekday(words[1]); ok {
+				r.kind, r.ordinal, r.weekday = recurOrdinalWeekday, ordinal, wd
+				return r, true
+			}
+		}
+		month, ok := parseMonth(words[0])
+		dayWord := words[1]
+		if !ok {
+			month, ok = parseMonth(words[1])
+			dayWord = words[0]
+		}
+		if ok {
+			day, ok := parseMonthDayNumber(dayWord)
+			r.kind, r.month, r.day = recurYearDay, month, day
+			return r, ok && day > 0
+		}
+	}
+	// A list of days of the week, e.g., "mon fri" or "mon and fri".
+	r.kind = recurWeekdays
+	found := false
+	for _, w := range words {
+		if w == "and" {
+			continue
+		}
+		wd, ok := parseWeekday(w)
+		if !ok {
+			return nil, false
+		}
+		r.weekdays[wd], found = true, true
+	}
+	// Otherwise, there would be no occurrences, e.g., for "every and".
+	return r, found
+}
+
+// parseMonthDayNumber parses a day of the month, e.g., 15 or 15th.
+func parseMonthDayNumber(w string) (int, bool) {
+	day, err := strconv.Atoi(strings.TrimRight(w, "stndrh"))
+	return day, err == nil && day >= 1 && day <= 31
+}
+
+func parseOrdinal(w string) (int, bool) {
+	switch w {
+	case "1st", "first":
+		return 1, true
+	case "2nd", "second":
+		return 2, true
+	case "3rd", "third":
+		return 3, true
+	case "4th", "fourth":
+		return 4, true
+	case "5th", "fifth":
+		return 5, true
+	case "last":
+		return -1, true
+	}
+	return 0, false
+}
+
+// startOfDay returns the midnight starting the day of t, in t's location.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// clock returns the given day at the time of the day of the recurrence, if any, otherwise at the time of the day
+// of t.
+func (r *Recurrence) clock(day, t time.Time) time.Time {
+	if r.timed {
+		return time.Date(day.Year(), day.Month(), day.Day(), r.hour, r.minute, 0, 0, day.Location())
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, day.Location())
+}
+
+// First returns the first occurrence at or after t, which is how the servers set the due date of an item given a
+// recurring due string. Without a time in the due string, occurrences start at midnight.
+func (r *Recurrence) First(t time.Time) time.Time {
+	start := startOfDay(t)
+	if r.timed && r.clock(start, start).Before(t) {
+		start = start.AddDate(0, 0, 1)
+	}
+	if r.kind != recurInterval {
+		start = r.nextDay(start.AddDate(0, 0, -1))
+	}
+	r.anchor = start.Day()
+	return r.clock(start, start)
+}
+
+// Advance returns the occurrence following the one at t. Its time of the day is the one in the due string, if any,
+// otherwise the same as t's. Unless First was called, the first t given is taken as the first occurrence, e.g.,
+// every month from Jan 31 is Feb 29, Mar 31, and so on, rather than Mar 29.
+func (r *Recurrence) Advance(t time.Time) time.Time {
+	if r.anchor == 0 {
+		r.anchor = t.Day()
+	}
+	return r.clock(r.nextDay(startOfDay(t)), t)
+}
+
+// Occurrences returns the n occurrences following the one at t.
+func (r *Recurrence) Occurrences(t time.Time, n int) []time.Time {
+	occurrences := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		t = r.Advance(t)
+		occurrences = append(occurrences, t)
+	}
+	return occurrences
+}
+
+// nextDay returns the day of the occurrence following the one on the given day.
+func (r *Recurrence) nextDay(day time.Time) time.Time {
+	switch r.kind {
+	case recurInterval:
+		switch r.unit {
+		case "week":
+			return day.AddDate(0, 0, 7*r.interval)
+		case "month":
+			return r.addMonths(day, r.interval)
+		case "year":
+			return r.addMonths(day, 12*r.interval)
+		default:
+			return day.AddDate(0, 0, r.interval)
+		}
+	case recurWeekdays:
+		next := day.AddDate(0, 0, 1)
+		for i := 0; i < 7 && !r.weekdays[next.Weekday()]; i++ {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case recurOrdinalWeekday:
+		// The fifth occurrence of a weekday is missing in some months, but not in all of the next few.
+		for i := 0; i < 12; i++ {
+			first := time.Date(day.Year(), day.Month()+time.Month(i), 1, 0, 0, 0, 0, day.Location())
+			if next, ok := ordinalWeekday(first, r.ordinal, r.weekday); ok && next.After(day) {
+				return next
+			}
+		}
+	case recurMonthDay:
+		for i := 0; i < 2; i++ {
+			first := time.Date(day.Year(), day.Month()+time.Month(i), 1, 0, 0, 0, 0, day.Location())
+			if next := dayOfMonth(first, r.day); next.After(day) {
+				return next
+			}
+		}
+	case recurYearDay:
+		for i := 0; i < 2; i++ {
+			first := time.Date(day.Year()+i, r.month, 1, 0, 0, 0, 0, day.Location())
+			if next := dayOfMonth(first, r.day); next.After(day) {
+				return next
+			}
+		}
+	}
+	// Not reached for recurrences built by ParseRecurrence.
+	return day.AddDate(0, 0, 1)
+}
+
+// addMonths is like time.AddDate, except that the day is clamped to the end of the month, e.g., Jan 31 plus one
+// month is Feb 28 or 29, rather than some day in March.
+func addMonths(day time.Time, n int) time.Time {
+	first := time.Date(day.Year(), day.Month()+time.Month(n), 1, 0, 0, 0, 0, day.Location())
+	return dayOfMonth(first, day.Day())
+}
+
+// addMonths is like the addMonths function, but clamps the anchor day of the month rather than day's, so that
+// occurrences don't drift to earlier days after a short month.
+func (r *Recurrence) addMonths(day time.Time, n int) time.Time {
+	first := time.Date(day.Year(), day.Month()+time.Month(n), 1, 0, 0, 0, 0, day.Location())
+	return dayOfMonth(first, r.anchor)
+}
+
+// dayOfMonth returns the given day in the month starting at first, or the last day if the month is shorter or the
+// day is -1.
+func dayOfMonth(first time.Time, day int) time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	if day == -1 || day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// ordinalWeekday returns, e.g., the third friday in the month starting at first. It returns false if there's no
+// such day.
+func ordinalWeekday(first time.Time, ordinal int, wd time.Weekday) (time.Time, bool) {
+	if ordinal == -1 {
+		last := first.AddDate(0, 1, -1)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(wd) + 7) % 7)), true
+	}
+	day := nextWeekday(first, wd).AddDate(0, 0, 7*(ordinal-1))
+	return day, day.Month() == first.Month()
+}
+
+// Recurrence parses the due string of a recurring due date, see ParseRecurrence.
+func (due Due) Recurrence() (*Recurrence, error) {
+	if !due.IsRecurring {
+		return nil, fmt.Errorf("due %q: %w", due.String, errNotRecurring)
+	}
+	return ParseRecurrence(due.String)
+}
+
+// zone returns the location in which the occurrences are computed: the due date's own timezone for fixed-zone due
+// dates, if known, otherwise the given one, i.e., the user's.
+func (due Due) zone(loc *time.Location) *time.Location {
+	if due.IsAllDay() || due.IsFloating() || due.Timezone == "" {
+		return loc
+	}
+	if zone, err := time.LoadLocation(due.Timezone); err == nil {
+		return zone
+	}
+	return loc
+}
+
+// Occurrences returns the n occurrences of a recurring due date following the current one, in the given location,
+// which should be the user's timezone. Like TimeIn, all-day occurrences are at the end of the day.
+func (due Due) Occurrences(n int, loc *time.Location) ([]time.Time, error) {
+	r, err := due.Recurrence()
+	if err != nil {
+		return nil, err
+	}
+	current := due.TimeIn(due.zone(loc))
+	if current.IsZero() {
+		return nil, fmt.Errorf("due %q: no current occurrence", due.String)
+	}
+	occurrences := r.Occurrences(current, n)
+	for i, t := range occurrences {
+		occurrences[i] = t.In(loc)
+	}
+	return occurrences, nil
+}
+
+// Next returns the due date following the completion of a recurring item at the given time, i.e., the first
+// occurrence after that time, counting from the current occurrence, or from the completion for "every!" due
+// strings.
+func (due Due) Next(completed time.Time, loc *time.Location) (Due, error) {
+	r, err := due.Recurrence()
+	if err != nil {
+		return Due{}, err
+	}
+	zone := due.zone(loc)
+	t := due.TimeIn(zone)
+	if t.IsZero() {
+		return Due{}, fmt.Errorf("due %q: no current occurrence", due.String)
+	}
+	if r.FromCompletion {
+		t = r.clock(startOfDay(completed.In(zone)), t)
+	}
+	t = r.Advance(t)
+	// Occurrences missed while the item was overdue are skipped.
+	for !t.After(completed) {
+		t = r.Advance(t)
+	}
+	next := due
+	switch {
+	case due.IsAllDay():
+		next.Date = t.Format(allDayLayout)
+	case due.IsFloating():
+		next.Date = t.Format(floatingLayout)
+	default:
+		next.Date = t.UTC().Format(fixedZoneLayout)
+	}
+	return next, nil
+}
+
+// nextDueArgs returns the arguments to patch a recurring item with its next due date after completion now, or
+// false if the due string is not understood. Must be called with c.mu held.
+func (c *Client) nextDueArgs(item *Item) (map[string]json.RawMessage, bool) {
+	next, err := item.Due.Next(time.Now(), c.locationLocked())
+	if err != nil {
+		return nil, false
+	}
+	b, err := json.Marshal(next)
+	if err != nil {
+		return nil, false
+	}
+	return map[string]json.RawMessage{"id": jsonInt(item.ID), "due": b}, true
+}
@@ -0,0 +1,163 @@
+package todoist_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Examples from https://get.todoist.help/hc/en-us/articles/360000636289.
+func TestRecurrenceOccurrences(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2020, month, d, 0, 0, 0, 0, time.UTC) }
+	// A Thursday.
+	start := day(time.January, 2)
+	testCases := []struct {
+		input    string
+		expected []time.Time
+	}{
+		{"every day", []time.Time{day(1, 3), day(1, 4), day(1, 5)}},
+		{"daily", []time.Time{day(1, 3), day(1, 4), day(1, 5)}},
+		{"every other day", []time.Time{day(1, 4), day(1, 6), day(1, 8)}},
+		{"every 3 days", []time.Time{day(1, 5), day(1, 8), day(1, 11)}},
+		{"every weekday", []time.Time{day(1, 3), day(1, 6), day(1, 7)}},
+		{"every workday", []time.Time{day(1, 3), day(1, 6), day(1, 7)}},
+		{"every weekend", []time.Time{day(1, 4), day(1, 5), day(1, 11)}},
+		{"every mon, fri", []time.Time{day(1, 3), day(1, 6), day(1, 10)}},
+		{"every Monday and Friday", []time.Time{day(1, 3), day(1, 6), day(1, 10)}},
+		{"every 2 weeks", []time.Time{day(1, 16), day(1, 30), day(2, 13)}},
+		{"every 3rd friday", []time.Time{day(1, 17), day(2, 21), day(3, 20)}},
+		{"every last monday", []time.Time{day(1, 27), day(2, 24), day(3, 30)}},
+		{"every 5th friday", []time.Time{day(1, 31), day(5, 29), day(7, 31)}},
+		{"every 15th", []time.Time{day(1, 15), day(2, 15), day(3, 15)}},
+		{"every last day", []time.Time{day(1, 31), day(2, 29), day(3, 31)}},
+		{"every month", []time.Time{day(2, 2), day(3, 2), day(4, 2)}},
+		{"every jan 27", []time.Time{day(1, 27), day(1, 27).AddDate(1, 0, 0), day(1, 27).AddDate(2, 0, 0)}},
+		{"every year", []time.Time{day(1, 2).AddDate(1, 0, 0), day(1, 2).AddDate(2, 0, 0), day(1, 2).AddDate(3, 0, 0)}},
+		{"every! 3 days", []time.Time{day(1, 5), day(1, 8), day(1, 11)}},
+		{"every monday 9am", []time.Time{day(1, 6).Add(9 * time.Hour), day(1, 13).Add(9 * time.Hour), day(1, 20).Add(9 * time.Hour)}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			r, err := todoist.ParseRecurrence(tc.input)
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, r.Occurrences(start, len(tc.expected)))
+		})
+	}
+	for _, input := range []string{"every", "every 0 days", "every blue moon", "every feb 30x", "monday", "every and", "every and and"} {
+		_, err := todoist.ParseRecurrence(input)
+		assert.NotNil(t, err, input)
+	}
+}
+
+// Monthly and yearly occurrences keep to the day of the month of the first one, as far as each month allows.
+func TestRecurrenceAtMonthEnd(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	testCases := []struct {
+		input    string
+		start    time.Time
+		expected []time.Time
+	}{
+		{"every month", day(2020, 1, 31), []time.Time{day(2020, 2, 29), day(2020, 3, 31), day(2020, 4, 30), day(2020, 5, 31)}},
+		{"every month", day(2020, 1, 30), []time.Time{day(2020, 2, 29), day(2020, 3, 30), day(2020, 4, 30)}},
+		{"every 2 months", day(2019, 12, 31), []time.Time{day(2020, 2, 29), day(2020, 4, 30), day(2020, 6, 30), day(2020, 8, 31)}},
+		{"every year", day(2020, 2, 29), []time.Time{day(2021, 2, 28), day(2022, 2, 28), day(2023, 2, 28), day(2024, 2, 29)}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input+" from "+tc.start.Format("2006-01-02"), func(t *testing.T) {
+			r, err := todoist.ParseRecurrence(tc.input)
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, r.Occurrences(tc.start, len(tc.expected)))
+		})
+	}
+	r, err := todoist.ParseRecurrence("every month")
+	require.Nil(t, err)
+	first := r.First(day(2020, 1, 31))
+	assert.Equal(t, day(2020, 3, 31), r.Advance(r.Advance(first)))
+}
+
+func TestRecurrenceFirst(t *testing.T) {
+	now := time.Date(2020, time.January, 2, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"every day", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"every day 9am", time.Date(2020, 1, 3, 9, 0, 0, 0, time.UTC)},
+		{"every day at 13:00", time.Date(2020, 1, 2, 13, 0, 0, 0, time.UTC)},
+		{"every thursday", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"every monday", time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"every 2nd", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"every 1st", time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			r, err := todoist.ParseRecurrence(tc.input)
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, r.First(now))
+		})
+	}
+}
+
+func TestDueNext(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	require.Nil(t, err)
+	completed := time.Date(2020, time.January, 2, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name      string
+		due       todoist.Due
+		completed time.Time
+		expected  string
+	}{
+		{"all-day", todoist.Due{Date: "2020-01-02", String: "every day"}, completed, "2020-01-03"},
+		{"overdue", todoist.Due{Date: "2019-12-25", String: "every day"}, completed, "2020-01-02"},
+		{"early", todoist.Due{Date: "2020-01-06T09:00:00", String: "every monday 9am"}, completed, "2020-01-13T09:00:00"},
+		{"from completion", todoist.Due{Date: "2019-12-20", String: "every! 3 days"}, completed, "2020-01-05"},
+		{"month end", todoist.Due{Date: "2019-10-31", String: "every month"}, completed, "2020-01-31"},
+		{"fixed-zone", todoist.Due{Date: "2020-01-02T08:00:00Z", Timezone: "Europe/Rome", String: "every day"}, completed,
+			"2020-01-03T08:00:00Z"},
+		// Nine in the morning in Rome, across the change to summer time.
+		{"daylight saving", todoist.Due{Date: "2020-03-28T08:00:00Z", Timezone: "Europe/Rome", String: "every day"},
+			time.Date(2020, time.March, 28, 12, 0, 0, 0, time.UTC), "2020-03-29T07:00:00Z"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.due.IsRecurring = true
+			next, err := tc.due.Next(tc.completed, rome)
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, next.Date)
+			assert.Equal(t, tc.due.String, next.String)
+		})
+	}
+	_, err = todoist.Due{Date: "2020-01-02", String: "every day"}.Next(completed, rome)
+	assert.NotNil(t, err)
+
+	occurrences, err := todoist.Due{Date: "2020-01-06T09:00:00", String: "every monday 9am", IsRecurring: true}.Occurrences(2, rome)
+	require.Nil(t, err)
+	assert.Equal(t, []time.Time{time.Date(2020, 1, 13, 9, 0, 0, 0, rome), time.Date(2020, 1, 20, 9, 0, 0, 0, rome)}, occurrences)
+}
+
+func TestRecurringCompletionOffline(t *testing.T) {
+	ts := newStaticServer(`{"sync_token": "t", "items": [
+		{"id": 1, "content": "weekly review", "due": {"date": "2999-01-01", "string": "every week", "is_recurring": true}},
+		{"id": 2, "content": "something odd", "due": {"date": "2999-01-01", "string": "every blue moon", "is_recurring": true}}
+	]}`)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL), todoist.WithTimezone("UTC"))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	c.QueueItemClose(1)
+	c.QueueItemClose(2)
+	item, _ := c.ItemByID(1)
+	assert.Equal(t, 0, item.Checked)
+	assert.Equal(t, "2999-01-08", item.Due.Date)
+	// Until the next Pull, not understood due strings stay as they are.
+	item, _ = c.ItemByID(2)
+	assert.Equal(t, 0, item.Checked)
+	assert.Equal(t, "2999-01-01", item.Due.Date)
+}
@@ -0,0 +1,154 @@
+package todoist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// These constants are the possible values of Reminder.Type.
+const (
+	ReminderAbsolute = "absolute" // At the time of Reminder.Due.
+	ReminderRelative = "relative" // Reminder.MinuteOffset minutes before the item is due.
+	ReminderLocation = "location" // When arriving at or leaving a place. Not supported by Notifier.
+)
+
+// Reminder partially describes a reminder for an item. Treat as read-only, use ReminderPatch for add/update
+// commands.
+type Reminder struct {
+	ID           int64  `json:"id"`
+	ItemID       int64  `json:"item_id"`
+	NotifyUID    int64  `json:"notify_uid"` // The user to remind.
+	Service      string `json:"service"`    // How the apps remind the user, i.e., "email", "mobile", or "push".
+	Type         string `json:"type"`       // See ReminderAbsolute and the other constants.
+	Due          *Due   `json:"due"`        // Only for absolute reminders.
+	MinuteOffset int    `json:"mm_offset"`  // Only for relative reminders.
+	IsDeleted    int    `json:"is_deleted"`
+}
+
+// Time computes when the reminder should go off, given the item it refers to and the user's timezone (see
+// Client.Location). It returns false for location reminders, and for relative reminders of items that aren't due.
+func (reminder *Reminder) Time(item *Item, loc *time.Location) (time.Time, bool) {
+	switch reminder.Type {
+	case ReminderAbsolute:
+		if reminder.Due == nil {
+			return time.Time{}, false
+		}
+		return reminder.Due.TimeIn(loc), true
+	case ReminderRelative:
+		if item == nil || item.Due == nil {
+			return time.Time{}, false
+		}
+		return item.Due.TimeIn(loc).Add(-time.Duration(reminder.MinuteOffset) * time.Minute), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// ReminderPatch is used to add or update reminders (see, e.g., QueueReminderAdd, QueueReminderUpdate). Errors in
+// the setter methods surface when marshalling to JSON, as for ItemPatch.
+type ReminderPatch struct {
+	id    int64
+	attrs map[string]string
+	err   error
+}
+
+func NewReminderPatch(id int64) *ReminderPatch {
+	reminder := new(ReminderPatch)
+	reminder.id = id
+	reminder.attrs = make(map[string]string)
+	return reminder
+}
+
+// WithItemID sets the item of a new reminder. The id can be a temporary id, see ItemPatch.WithLabels.
+func (reminder *ReminderPatch) WithItemID(value ID) *ReminderPatch {
+	if reminder.err != nil {
+		return reminder
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		reminder.err = fmt.Errorf("setting item id: %w", err)
+	} else {
+		reminder.attrs["item_id"] = string(b)
+	}
+	return reminder
+}
+
+// WithDue makes the reminder an absolute one, going off at the given time, usually a fixed-zone due date (see
+// DueAt).
+func (reminder *ReminderPatch) WithDue(due Due) *ReminderPatch {
+	reminder.attrs["type"] = strconv.Quote(ReminderAbsolute)
+	reminder.attrs["due"] = due.args()
+	return reminder
+}
+
+// WithMinuteOffset makes the reminder a relative one, going off the given number of minutes before the item is
+// due.
+func (reminder *ReminderPatch) WithMinuteOffset(value int) *ReminderPatch {
+	reminder.attrs["type"] = strconv.Quote(ReminderRelative)
+	reminder.attrs["mm_offset"] = strconv.Itoa(value)
+	return reminder
+}
+
+// WithService sets how the apps remind the user, i.e., "email", "mobile", or "push".
+func (reminder *ReminderPatch) WithService(value string) *ReminderPatch {
+	reminder.attrs["service"] = strconv.Quote(value)
+	return reminder
+}
+
+// MarshalJSON implements json.Marshaler.
+func (reminder *ReminderPatch) MarshalJSON() ([]byte, error) {
+	if reminder.err != nil {
+		return nil, reminder.err
+	}
+	buf := bytes.NewBuffer(nil)
+	_, _ = fmt.Fprintf(buf, `{"id":%d`, reminder.id)
+	for k, v := range reminder.attrs {
+		_, _ = fmt.Fprintf(buf, `,%q:%s`, k, v)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+type reminderPredicate func(*Reminder) bool
+
+type ReminderScan struct {
+	client     *Client
+	predicates []reminderPredicate
+}
+
+func (s *ReminderScan) WithItemID(value int64) *ReminderScan {
+	s.predicates = append(s.predicates, func(reminder *Reminder) bool {
+		return reminder.ItemID == value
+	})
+	return s
+}
+
+func (s *ReminderScan) Results() []*Reminder {
+	s.client.mu.RLock()
+	defer s.client.mu.RUnlock()
+	var results []*Reminder
+	for _, reminder := range s.client.view.Reminders {
+		if s.match(reminder) {
+			results = append(results, reminder)
+		}
+	}
+	return results
+}
+
+func (s *ReminderScan) match(reminder *Reminder) bool {
+	for _, match := range s.predicates {
+		if !match(reminder) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Client) SearchReminders() *ReminderScan {
+	return &ReminderScan{
+		client: c,
+	}
+}
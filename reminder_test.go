@@ -0,0 +1,114 @@
+package todoist_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pullWithReminders = `{
+	"sync_token": "t",
+	"items": [
+		{"id": 1, "project_id": 10, "content": "call mom", "due": {"date": "2020-01-02T15:00:00Z"}},
+		{"id": 2, "project_id": 10, "content": "no due date"},
+		{"id": 3, "project_id": 10, "content": "done", "checked": 1, "due": {"date": "2020-01-02T15:00:00Z"}}
+	],
+	"projects": [{"id": 10, "name": "Inbox"}],
+	"reminders": [
+		{"id": 50, "item_id": 1, "type": "relative", "mm_offset": 30, "service": "push"},
+		{"id": 51, "item_id": 2, "type": "absolute", "due": {"date": "2020-01-02T14:00:00Z"}},
+		{"id": 52, "item_id": 2, "type": "relative", "mm_offset": 10},
+		{"id": 53, "item_id": 3, "type": "relative", "mm_offset": 0},
+		{"id": 54, "item_id": 1, "type": "location", "name": "Home"},
+		{"id": 55, "item_id": 1, "type": "absolute", "due": {"date": "2020-01-02T13:00:00Z"}, "is_deleted": 1}
+	]
+}`
+
+func TestReminderPatch(t *testing.T) {
+	testCases := []struct {
+		setter   func(*todoist.ReminderPatch)
+		expected map[string]interface{}
+	}{
+		{
+			setter: func(r *todoist.ReminderPatch) {
+				r.WithItemID(todoist.NewTemporaryID("abc")).WithMinuteOffset(30)
+			},
+			expected: map[string]interface{}{"id": 0.0, "item_id": "abc", "type": "relative", "mm_offset": 30.0},
+		},
+		{
+			setter: func(r *todoist.ReminderPatch) {
+				r.WithDue(todoist.DueAt(time.Date(2020, time.January, 2, 15, 0, 0, 0, time.UTC))).WithService("email")
+			},
+			expected: map[string]interface{}{"id": 0.0, "type": "absolute", "service": "email",
+				"due": map[string]interface{}{"date": "2020-01-02T15:00:00Z", "timezone": "UTC"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			reminder := todoist.NewReminderPatch(0)
+			tc.setter(reminder)
+			b, err := json.Marshal(reminder)
+			require.Nil(t, err)
+			var actual map[string]interface{}
+			require.Nil(t, json.Unmarshal(b, &actual))
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestReminders(t *testing.T) {
+	ts := newStaticServer(pullWithReminders)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	assert.Len(t, c.SearchReminders().Results(), 5)
+	reminders := c.SearchReminders().WithItemID(1).Results()
+	assert.Len(t, reminders, 2)
+
+	c.QueueReminderAdd(todoist.NewReminderPatch(0).WithItemID(todoist.NewID(1)).WithMinuteOffset(5))
+	c.QueueReminderUpdate(todoist.NewReminderPatch(50).WithMinuteOffset(60))
+	c.QueueReminderDelete(51)
+	assert.Len(t, c.SearchReminders().WithItemID(1).Results(), 3)
+	assert.Len(t, c.SearchReminders().WithItemID(2).Results(), 1)
+	for _, r := range c.SearchReminders().WithItemID(1).Results() {
+		if r.ID == 50 {
+			assert.Equal(t, 60, r.MinuteOffset)
+		}
+	}
+
+	// Reminders are deleted along with their item.
+	c.QueueItemDelete(1)
+	assert.Empty(t, c.SearchReminders().WithItemID(1).Results())
+}
+
+func TestNotifier(t *testing.T) {
+	ts := newStaticServer(pullWithReminders)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL), todoist.WithTimezone("UTC"))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	var buf bytes.Buffer
+	// The notifier was created long after the reminders went off.
+	n := todoist.NewNotifier(c, todoist.WriterSink(&buf))
+	assert.Empty(t, n.Check(time.Now().Add(time.Hour)))
+
+	n = todoist.NewNotifier(c, todoist.WriterSink(&buf), todoist.WithLookback(time.Since(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))))
+	fired := n.Check(time.Date(2020, 1, 2, 14, 30, 0, 0, time.UTC))
+	require.Len(t, fired, 2)
+	assert.Equal(t, int64(51), fired[0].Reminder.ID)
+	assert.Equal(t, int64(50), fired[1].Reminder.ID)
+	assert.Equal(t, time.Date(2020, 1, 2, 14, 30, 0, 0, time.UTC), fired[1].Time)
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+	// Formatted in the user's timezone, whatever the local one.
+	assert.Contains(t, buf.String(), "2020-01-02 14:30 call mom")
+	// Reminders fire only once.
+	assert.Empty(t, n.Check(time.Date(2020, 1, 2, 16, 0, 0, 0, time.UTC)))
+}
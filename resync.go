@@ -0,0 +1,158 @@
+package todoist
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// EntityDiff lists the ids of the entities of one type that a full sync added to, removed from, or changed in the
+// client's data.
+type EntityDiff struct {
+	Added   []int64
+	Removed []int64
+	Changed []int64
+}
+
+// Empty reports whether nothing changed.
+func (d EntityDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// SyncDiff describes how a full sync changed the client's data. See Resync.
+type SyncDiff struct {
+	Items     EntityDiff
+	Archive   EntityDiff // Only used with WithCompletedArchive.
+	Labels    EntityDiff
+	Notes     EntityDiff
+	Projects  EntityDiff
+	Sections  EntityDiff
+	Reminders EntityDiff
+	Filters   EntityDiff
+}
+
+// Empty reports whether the client's data was already in sync.
+func (d *SyncDiff) Empty() bool {
+	return d.Items.Empty() && d.Archive.Empty() && d.Labels.Empty() && d.Notes.Empty() && d.Projects.Empty() &&
+		d.Sections.Empty() && d.Reminders.Empty() && d.Filters.Empty()
+}
+
+// String implements fmt.Stringer, e.g., "items: 1 added, 0 removed, 2 changed".
+func (d *SyncDiff) String() string {
+	if d.Empty() {
+		return "no changes"
+	}
+	var b bytes.Buffer
+	for _, entity := range []struct {
+		name string
+		diff EntityDiff
+	}{
+		{"items", d.Items},
+		{"archive", d.Archive},
+		{"labels", d.Labels},
+		{"notes", d.Notes},
+		{"projects", d.Projects},
+		{"sections", d.Sections},
+		{"reminders", d.Reminders},
+		{"filters", d.Filters},
+	} {
+		if entity.diff.Empty() {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("; ")
+		}
+		_, _ = fmt.Fprintf(&b, "%s: %d added, %d removed, %d changed",
+			entity.name, len(entity.diff.Added), len(entity.diff.Removed), len(entity.diff.Changed))
+	}
+	return b.String()
+}
+
+// Resync discards the client's data, except for the queue of commands, and downloads everything again, as if the
+// client had never synced before. Use it when the data seems to have drifted from what's on the servers. It
+// returns what changed in the client's data.
+func (c *Client) Resync() (*SyncDiff, error) {
+	return c.ResyncContext(context.Background())
+}
+
+// ResyncContext is like Resync, but the remote call is bound to the given context.
+func (c *Client) ResyncContext(ctx context.Context) (*SyncDiff, error) {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	pr, err := c.fetch(ctx, "*")
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stale := c.replaceData(pr)
+	c.lastPulled = time.Now()
+	return &SyncDiff{
+		Items:     diffEntities(stale.Items, c.data.Items),
+		Archive:   diffEntities(stale.Archive, c.data.Archive),
+		Labels:    diffEntities(stale.Labels, c.data.Labels),
+		Notes:     diffEntities(stale.Notes, c.data.Notes),
+		Projects:  diffEntities(stale.Projects, c.data.Projects),
+		Sections:  diffEntities(stale.Sections, c.data.Sections),
+		Reminders: diffEntities(stale.Reminders, c.data.Reminders),
+		Filters:   diffEntities(stale.Filters, c.data.Filters),
+	}, nil
+}
+
+// replaceData replaces the client's data with the response to a full sync, which doesn't mention deleted entities,
+// keeping the queue of commands and what the sync API doesn't return. It returns the replaced data. Must be called
+// with c.mu held for writing.
+func (c *Client) replaceData(pr *pullResponse) (stale *clientData) {
+	stale = c.data
+	c.data = &clientData{
+		Version:      stale.Version,
+		Items:        make(map[int64]*Item),
+		Labels:       make(map[int64]*Label),
+		Notes:        make(map[int64]*Note),
+		ProjectNotes: make(map[int64]*Note),
+		Projects:     make(map[int64]*Project),
+		Sections:     make(map[int64]*Section),
+		Reminders:    make(map[int64]*Reminder),
+		Filters:      make(map[int64]*Filter),
+		Archive:      make(map[int64]*Item, len(stale.Archive)),
+		Completed:    stale.Completed, // Not returned by the sync API, see FetchCompleted.
+		User:         stale.User,
+		Commands:     stale.Commands,
+		LocalIDs:     stale.LocalIDs,
+		LastLocalID:  stale.LastLocalID,
+	}
+	// Full syncs don't return completed items either. Archived items are only dropped if the response says they
+	// were deleted or uncompleted.
+	for id, item := range stale.Archive {
+		c.data.Archive[id] = item
+	}
+	c.incorporate(pr)
+	c.refreshView()
+	return stale
+}
+
+// diffEntities compares two maps from ids to entities, e.g., two map[int64]*Item values.
+func diffEntities(before, after interface{}) (diff EntityDiff) {
+	b, a := reflect.ValueOf(before), reflect.ValueOf(after)
+	for _, key := range a.MapKeys() {
+		stale := b.MapIndex(key)
+		switch {
+		case !stale.IsValid():
+			diff.Added = append(diff.Added, key.Int())
+		case !reflect.DeepEqual(stale.Interface(), a.MapIndex(key).Interface()):
+			diff.Changed = append(diff.Changed, key.Int())
+		}
+	}
+	for _, key := range b.MapKeys() {
+		if !a.MapIndex(key).IsValid() {
+			diff.Removed = append(diff.Removed, key.Int())
+		}
+	}
+	for _, ids := range [][]int64{diff.Added, diff.Removed, diff.Changed} {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	return diff
+}
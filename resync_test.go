@@ -0,0 +1,82 @@
+package todoist_test
+
+import (
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResync(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	store := todoist.NewMemoryStore()
+	require.Nil(t, store.Save([]byte(`{
+		"version": 1,
+		"sync_token": "stale",
+		"items": {
+			"1": {"id": 1, "project_id": 10, "labels": [20], "content": "drifted", "child_order": 1},
+			"99": {"id": 99, "project_id": 10, "content": "deleted elsewhere"}
+		},
+		"labels": {"20": {"id": 20, "name": "next"}},
+		"notes": {},
+		"project_notes": {},
+		"projects": {"10": {"id": 10, "name": "Inbox", "child_order": 1}}
+	}`)))
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL), todoist.WithStore(store))
+	require.Nil(t, err)
+	require.Nil(t, c.Load())
+	c.QueueItemUpdate(todoist.NewItemPatch(1).WithContent("pending"))
+
+	diff, err := c.Resync()
+	require.Nil(t, err)
+	assert.Equal(t, []int64{2}, diff.Items.Added)
+	assert.Equal(t, []int64{99}, diff.Items.Removed)
+	assert.Equal(t, []int64{1}, diff.Items.Changed)
+	assert.Equal(t, []int64{30}, diff.Notes.Added)
+	assert.True(t, diff.Labels.Empty())
+	assert.True(t, diff.Projects.Empty())
+	assert.Equal(t, "items: 1 added, 1 removed, 1 changed; notes: 1 added, 0 removed, 0 changed", diff.String())
+
+	// Queued commands survive.
+	item, _ := c.ItemByID(1)
+	assert.Equal(t, "pending", item.Content)
+
+	diff, err = c.Resync()
+	require.Nil(t, err)
+	assert.True(t, diff.Empty())
+}
+
+func TestResyncKeepsArchive(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	store := todoist.NewMemoryStore()
+	require.Nil(t, store.Save([]byte(`{
+		"version": 1,
+		"sync_token": "stale",
+		"items": {},
+		"labels": {},
+		"notes": {},
+		"project_notes": {},
+		"projects": {},
+		"archive": {
+			"1": {"id": 1, "project_id": 10, "content": "uncompleted elsewhere", "checked": 1},
+			"50": {"id": 50, "project_id": 10, "content": "done", "checked": 1}
+		}
+	}`)))
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL), todoist.WithStore(store),
+		todoist.WithCompletedArchive())
+	require.Nil(t, err)
+	require.Nil(t, c.Load())
+
+	diff, err := c.Resync()
+	require.Nil(t, err)
+	assert.Equal(t, []int64{1}, diff.Archive.Removed)
+	assert.Empty(t, diff.Archive.Added)
+	archived := c.SearchArchive().Results()
+	require.Len(t, archived, 1)
+	assert.Equal(t, int64(50), archived[0].ID)
+	_, ok := c.ItemByID(1)
+	assert.True(t, ok)
+}
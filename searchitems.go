@@ -1,6 +1,9 @@
 package todoist
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type itemPredicate func(*Item) bool
 
@@ -12,6 +15,7 @@ func negate(p itemPredicate) itemPredicate {
 
 type ItemScan struct {
 	client     *Client
+	archive    bool // Whether to scan archived items instead of the others.
 	predicates []itemPredicate
 }
 
@@ -62,6 +66,79 @@ func (s *ItemScan) WithContent(needle string) *ItemScan {
 	return s
 }
 
+// WithPriority looks for items with any of the given priorities, from 1 (normal) to 4 (urgent).
+func (s *ItemScan) WithPriority(value ...int) *ItemScan {
+	s.predicates = append(s.predicates, func(item *Item) bool {
+		for _, priority := range value {
+			if item.Priority == priority {
+				return true
+			}
+		}
+		return false
+	})
+	return s
+}
+
+// WithParentID looks for the sub-tasks of the given item, or for top-level items if the id is zero.
+func (s *ItemScan) WithParentID(value int64) *ItemScan {
+	s.predicates = append(s.predicates, func(item *Item) bool {
+		return item.ParentID == value
+	})
+	return s
+}
+
+// WithSectionID looks for items in the given section, or for items outside sections if the id is zero.
+func (s *ItemScan) WithSectionID(value int64) *ItemScan {
+	s.predicates = append(s.predicates, func(item *Item) bool {
+		return item.SectionID == value
+	})
+	return s
+}
+
+// WithResponsibleUID looks for items assigned to the given user, or for unassigned items if the id is zero.
+func (s *ItemScan) WithResponsibleUID(value int64) *ItemScan {
+	s.predicates = append(s.predicates, func(item *Item) bool {
+		return item.ResponsibleUID == value
+	})
+	return s
+}
+
+// WithAddedByUID looks for items created by the given user.
+func (s *ItemScan) WithAddedByUID(value int64) *ItemScan {
+	s.predicates = append(s.predicates, func(item *Item) bool {
+		return item.AddedByUID == value
+	})
+	return s
+}
+
+// WithDescription looks for items whose description contains the given substring.
+func (s *ItemScan) WithDescription(needle string) *ItemScan {
+	s.predicates = append(s.predicates, func(item *Item) bool {
+		return strings.Contains(item.Description, needle)
+	})
+	return s
+}
+
+// WithAddedBetween looks for items created in the given interval, inclusive of from and exclusive of to.
+func (s *ItemScan) WithAddedBetween(from, to time.Time) *ItemScan {
+	s.predicates = append(s.predicates, func(item *Item) bool {
+		return within(item.AddedTime(), from, to)
+	})
+	return s
+}
+
+// WithCompletedBetween is like WithAddedBetween, for the completion time.
+func (s *ItemScan) WithCompletedBetween(from, to time.Time) *ItemScan {
+	s.predicates = append(s.predicates, func(item *Item) bool {
+		return within(item.CompletedTime(), from, to)
+	})
+	return s
+}
+
+func within(t, from, to time.Time) bool {
+	return !t.IsZero() && !t.Before(from) && t.Before(to)
+}
+
 func (s *ItemScan) WithDue() *ItemScan {
 	s.predicates = append(s.predicates, func(item *Item) bool {
 		return item.Due != nil
@@ -70,8 +147,14 @@ func (s *ItemScan) WithDue() *ItemScan {
 }
 
 func (s *ItemScan) Results() []*Item {
+	s.client.mu.RLock()
+	defer s.client.mu.RUnlock()
+	items := s.client.view.Items
+	if s.archive {
+		items = s.client.view.Archive
+	}
 	var results []*Item
-	for _, item := range s.client.data.Items {
+	for _, item := range items {
 		if s.match(item) {
 			results = append(results, item)
 		}
@@ -93,3 +176,11 @@ func (c *Client) SearchItems() *ItemScan {
 		client: c,
 	}
 }
+
+// SearchArchive is like SearchItems, but for the completed items archive. See WithCompletedArchive.
+func (c *Client) SearchArchive() *ItemScan {
+	return &ItemScan{
+		client:  c,
+		archive: true,
+	}
+}
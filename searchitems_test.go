@@ -0,0 +1,68 @@
+package todoist_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pullWithFullItems = `{
+	"sync_token": "t",
+	"items": [
+		{"id": 1, "project_id": 10, "content": "parent", "description": "the big one", "priority": 4,
+			"date_added": "2020-01-01T10:00:00Z", "added_by_uid": 5, "responsible_uid": 6},
+		{"id": 2, "project_id": 10, "parent_id": 1, "section_id": 40, "content": "child", "priority": 1,
+			"date_added": "2020-01-02T10:00:00Z", "added_by_uid": 5, "responsible_uid": null},
+		{"id": 3, "project_id": 10, "parent_id": null, "content": "done", "checked": 1, "priority": 1,
+			"date_added": "2020-01-03T10:00:00Z", "date_completed": "2020-01-04T10:00:00Z", "added_by_uid": 6}
+	],
+	"projects": [{"id": 10, "name": "Inbox"}]
+}`
+
+func itemIDs(items []*todoist.Item) []int64 {
+	var ids []int64
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestItemScanPredicates(t *testing.T) {
+	ts := newStaticServer(pullWithFullItems)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	item, ok := c.ItemByID(2)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), item.ParentID)
+	assert.Equal(t, int64(40), item.SectionID)
+	assert.Equal(t, int64(0), item.ResponsibleUID)
+
+	jan := func(day int) time.Time { return time.Date(2020, time.January, day, 0, 0, 0, 0, time.UTC) }
+	testCases := []struct {
+		name     string
+		scan     *todoist.ItemScan
+		expected []int64
+	}{
+		{"priority", c.SearchItems().WithPriority(4), []int64{1}},
+		{"priorities", c.SearchItems().WithPriority(1, 4).WithChecked(0), []int64{1, 2}},
+		{"parent", c.SearchItems().WithParentID(1), []int64{2}},
+		{"top-level", c.SearchItems().WithParentID(0), []int64{1, 3}},
+		{"section", c.SearchItems().WithSectionID(40), []int64{2}},
+		{"responsible", c.SearchItems().WithResponsibleUID(6), []int64{1}},
+		{"added by", c.SearchItems().WithAddedByUID(5), []int64{1, 2}},
+		{"description", c.SearchItems().WithDescription("big"), []int64{1}},
+		{"added", c.SearchItems().WithAddedBetween(jan(2), jan(4)), []int64{2, 3}},
+		{"completed", c.SearchItems().WithCompletedBetween(jan(4), jan(5)), []int64{3}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.expected, itemIDs(tc.scan.Results()))
+		})
+	}
+}
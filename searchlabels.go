@@ -15,8 +15,10 @@ func (s *LabelScan) WithIsDeleted(value int) *LabelScan {
 }
 
 func (s *LabelScan) Results() []*Label {
+	s.client.mu.RLock()
+	defer s.client.mu.RUnlock()
 	var results []*Label
-	for _, label := range s.client.data.Labels {
+	for _, label := range s.client.view.Labels {
 		if s.match(label) {
 			results = append(results, label)
 		}
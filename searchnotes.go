@@ -29,13 +29,15 @@ func (s *NoteScan) WithProjectID(project int64) *NoteScan {
 }
 
 func (s *NoteScan) Results() []*Note {
+	s.client.mu.RLock()
+	defer s.client.mu.RUnlock()
 	var results []*Note
-	for _, note := range s.client.data.Notes {
+	for _, note := range s.client.view.Notes {
 		if s.match(note) {
 			results = append(results, note)
 		}
 	}
-	for _, note := range s.client.data.ProjectNotes {
+	for _, note := range s.client.view.ProjectNotes {
 		if s.match(note) {
 			results = append(results, note)
 		}
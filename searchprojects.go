@@ -23,6 +23,21 @@ func (s *ProjectScan) WithIsDeleted(value int) *ProjectScan {
 	return s
 }
 
+// WithParentID looks for the sub-projects of the given project, or for top-level projects if the id is zero.
+func (s *ProjectScan) WithParentID(value int64) *ProjectScan {
+	s.predicates = append(s.predicates, func(p *Project) bool {
+		return p.ParentID == value
+	})
+	return s
+}
+
+func (s *ProjectScan) WithIsFavorite(value int) *ProjectScan {
+	s.predicates = append(s.predicates, func(p *Project) bool {
+		return p.IsFavorite == value
+	})
+	return s
+}
+
 // WithName looks for projects containing the given substring, case-insensitive.
 func (s *ProjectScan) WithName(needle string) *ProjectScan {
 	needle = strings.ToLower(needle)
@@ -33,8 +48,10 @@ func (s *ProjectScan) WithName(needle string) *ProjectScan {
 }
 
 func (s *ProjectScan) Results() []*Project {
+	s.client.mu.RLock()
+	defer s.client.mu.RUnlock()
 	var results []*Project
-	for _, project := range s.client.data.Projects {
+	for _, project := range s.client.view.Projects {
 		if s.match(project) {
 			results = append(results, project)
 		}
@@ -0,0 +1,60 @@
+package todoist
+
+import "strings"
+
+type sectionPredicate func(*Section) bool
+
+type SectionScan struct {
+	client     *Client
+	predicates []sectionPredicate
+}
+
+func (s *SectionScan) WithProjectID(value int64) *SectionScan {
+	s.predicates = append(s.predicates, func(section *Section) bool {
+		return section.ProjectID == value
+	})
+	return s
+}
+
+func (s *SectionScan) WithIsArchived(value bool) *SectionScan {
+	s.predicates = append(s.predicates, func(section *Section) bool {
+		return section.IsArchived == value
+	})
+	return s
+}
+
+// WithName looks for sections containing the given substring, case-insensitive.
+func (s *SectionScan) WithName(needle string) *SectionScan {
+	needle = strings.ToLower(needle)
+	s.predicates = append(s.predicates, func(section *Section) bool {
+		return strings.Contains(strings.ToLower(section.Name), needle)
+	})
+	return s
+}
+
+func (s *SectionScan) Results() []*Section {
+	s.client.mu.RLock()
+	defer s.client.mu.RUnlock()
+	var results []*Section
+	for _, section := range s.client.view.Sections {
+		if s.match(section) {
+			results = append(results, section)
+		}
+	}
+	return results
+}
+
+func (s *SectionScan) match(section *Section) bool {
+	for _, match := range s.predicates {
+		if !match(section) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Client) SearchSections() *SectionScan {
+	return &SectionScan{
+		client: c,
+	}
+}
@@ -0,0 +1,79 @@
+package todoist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Section partially describes a section, i.e., a group of items within a project. Treat as read-only, use
+// SectionPatch for add/update commands.
+type Section struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	ProjectID    int64  `json:"project_id"`
+	SectionOrder int    `json:"section_order"`
+	Collapsed    bool   `json:"collapsed"`
+	IsDeleted    bool   `json:"is_deleted"`
+	IsArchived   bool   `json:"is_archived"`
+}
+
+// SectionPatch is used to add or update sections (see, e.g., QueueSectionAdd, QueueSectionUpdate). Errors in the
+// setter methods surface when marshalling to JSON, as for ItemPatch.
+type SectionPatch struct {
+	id    int64
+	attrs map[string]string
+	err   error
+}
+
+func NewSectionPatch(id int64) *SectionPatch {
+	section := new(SectionPatch)
+	section.id = id
+	section.attrs = make(map[string]string)
+	return section
+}
+
+func (section *SectionPatch) WithName(value string) *SectionPatch {
+	section.attrs["name"] = fmt.Sprintf("%q", value)
+	return section
+}
+
+// WithProjectID sets the project of a new section. To move an existing section, use QueueSectionMove. The id can
+// be a temporary id, see ItemPatch.WithLabels.
+func (section *SectionPatch) WithProjectID(value ID) *SectionPatch {
+	if section.err != nil {
+		return section
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		section.err = fmt.Errorf("setting project id: %w", err)
+	} else {
+		section.attrs["project_id"] = string(b)
+	}
+	return section
+}
+
+func (section *SectionPatch) WithSectionOrder(value int) *SectionPatch {
+	section.attrs["section_order"] = strconv.Itoa(value)
+	return section
+}
+
+func (section *SectionPatch) WithCollapsed(value bool) *SectionPatch {
+	section.attrs["collapsed"] = strconv.FormatBool(value)
+	return section
+}
+
+// MarshalJSON implements json.Marshaler.
+func (section *SectionPatch) MarshalJSON() ([]byte, error) {
+	if section.err != nil {
+		return nil, section.err
+	}
+	buf := bytes.NewBuffer(nil)
+	_, _ = fmt.Fprintf(buf, `{"id":%d`, section.id)
+	for k, v := range section.attrs {
+		_, _ = fmt.Fprintf(buf, `,%q:%s`, k, v)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
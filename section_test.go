@@ -0,0 +1,152 @@
+package todoist_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pullWithSections = `{
+	"sync_token": "t",
+	"items": [
+		{"id": 1, "project_id": 10, "section_id": 40, "content": "in first section"},
+		{"id": 2, "project_id": 10, "section_id": 40, "parent_id": 1, "content": "sub-task"},
+		{"id": 3, "project_id": 10, "content": "no section"}
+	],
+	"projects": [{"id": 10, "name": "Inbox"}, {"id": 11, "name": "Work"}],
+	"sections": [
+		{"id": 40, "project_id": 10, "name": "First", "section_order": 1, "collapsed": false, "user_id": 1,
+			"sync_id": null, "is_deleted": false, "is_archived": false, "date_archived": null,
+			"date_added": "2019-10-07T07:09:27Z"},
+		{"id": 41, "project_id": 10, "name": "Second", "section_order": 2, "collapsed": true, "user_id": 1,
+			"sync_id": null, "is_deleted": false, "is_archived": false, "date_archived": null,
+			"date_added": "2019-10-07T07:09:28Z"},
+		{"id": 42, "project_id": 11, "name": "Archived", "section_order": 1, "collapsed": false, "user_id": 1,
+			"sync_id": null, "is_deleted": false, "is_archived": true, "date_archived": "2019-10-08T10:00:00Z",
+			"date_added": "2019-10-07T07:09:29Z"},
+		{"id": 43, "project_id": 11, "name": "Deleted", "section_order": 2, "collapsed": false, "user_id": 1,
+			"sync_id": null, "is_deleted": true, "is_archived": false, "date_archived": null,
+			"date_added": "2019-10-07T07:09:30Z"}
+	]
+}`
+
+func TestSectionPatch(t *testing.T) {
+	testCases := []struct {
+		id       int64
+		setter   func(*todoist.SectionPatch)
+		expected string
+	}{
+		{
+			id:       0,
+			setter:   func(*todoist.SectionPatch) {},
+			expected: `{"id":0}`,
+		},
+		{
+			id: 1,
+			setter: func(s *todoist.SectionPatch) {
+				s.WithName("foobar")
+			},
+			expected: `{"id":1,"name":"foobar"}`,
+		},
+		{
+			id: 0,
+			setter: func(s *todoist.SectionPatch) {
+				s.WithProjectID(todoist.NewTemporaryID("abc"))
+			},
+			expected: `{"id":0,"project_id":"abc"}`,
+		},
+		{
+			id: 2,
+			setter: func(s *todoist.SectionPatch) {
+				s.WithCollapsed(true)
+			},
+			expected: `{"id":2,"collapsed":true}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			section := todoist.NewSectionPatch(tc.id)
+			tc.setter(section)
+			b, err := json.Marshal(section)
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, string(b))
+		})
+	}
+}
+
+func TestPullSections(t *testing.T) {
+	ts := newStaticServer(pullWithSections)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	assert.Len(t, c.SearchSections().Results(), 3)
+	assert.Len(t, c.SearchSections().WithProjectID(10).Results(), 2)
+	assert.Len(t, c.SearchSections().WithIsArchived(false).Results(), 2)
+	sections := c.SearchSections().WithName("sec").Results()
+	require.Len(t, sections, 1)
+	assert.Equal(t, "Second", sections[0].Name)
+	assert.True(t, sections[0].Collapsed)
+	_, ok := c.SectionByID(43)
+	assert.False(t, ok)
+}
+
+func TestQueuedSectionCommandsAreAppliedLocally(t *testing.T) {
+	ts := newStaticServer(pullWithSections)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	tempID := c.QueueSectionAdd(todoist.NewSectionPatch(0).WithProjectID(todoist.NewID(11)).WithName("Third"))
+	c.QueueItemMoveToSection(todoist.NewID(3), todoist.NewTemporaryID(tempID))
+	sections := c.SearchSections().WithName("Third").Results()
+	require.Len(t, sections, 1)
+	item, _ := c.ItemByID(3)
+	assert.Equal(t, sections[0].ID, item.SectionID)
+	assert.Equal(t, int64(11), item.ProjectID)
+
+	c.QueueSectionUpdate(todoist.NewSectionPatch(40).WithName("Renamed"))
+	var reorder todoist.ReorderCommand
+	reorder.Add(40, 2)
+	reorder.Add(41, 1)
+	c.QueueSectionReorder(&reorder)
+	section, _ := c.SectionByID(40)
+	assert.Equal(t, "Renamed", section.Name)
+	assert.Equal(t, 2, section.SectionOrder)
+
+	// Items follow their section.
+	c.QueueSectionMove(todoist.NewID(40), todoist.NewID(11))
+	section, _ = c.SectionByID(40)
+	assert.Equal(t, int64(11), section.ProjectID)
+	for _, id := range []int64{1, 2} {
+		item, _ := c.ItemByID(id)
+		assert.Equal(t, int64(11), item.ProjectID)
+	}
+
+	c.QueueSectionArchive(41)
+	section, _ = c.SectionByID(41)
+	assert.True(t, section.IsArchived)
+
+	c.QueueSectionDelete(40)
+	_, ok := c.SectionByID(40)
+	assert.False(t, ok)
+	assert.Equal(t, []int64{3}, itemIDs(c.SearchItems().Results()))
+}
+
+func TestSectionReorderCommand(t *testing.T) {
+	var reorder todoist.ReorderCommand
+	reorder.Add(40, 2)
+	fs := newFakeServer()
+	defer fs.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL))
+	require.Nil(t, err)
+	c.QueueSectionReorder(&reorder)
+	b, err := json.Marshal(&reorder)
+	require.Nil(t, err)
+	assert.Equal(t, `{"sections":[{"id":40,"section_order":2}]}`, string(b))
+}
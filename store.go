@@ -0,0 +1,171 @@
+package todoist
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+)
+
+// ErrCorrupted can be returned by Load.
+var ErrCorrupted = errors.New("local data is corrupted")
+
+// Store persists the client's state between runs. See Load, Dump and WithStore.
+type Store interface {
+	// Load returns the state last saved with Save. If nothing was saved yet, the error wraps os.ErrNotExist.
+	// If the saved state is damaged, the error wraps ErrCorrupted.
+	Load() ([]byte, error)
+
+	// Save replaces the saved state.
+	Save(state []byte) error
+}
+
+// WithStore is a client option to choose where Load and Dump read and write the client's state. The default is
+// a FileStore in the lib/todoist directory within the user's home directory.
+func WithStore(store Store) clientOption {
+	return func(c *Client) error {
+		c.store = store
+		return nil
+	}
+}
+
+// FileStore keeps the state in the file state.data within a directory, along with its SHA-256 checksum in the
+// file state.sum, to detect corruption.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store for the given directory, which must exist. If the directory is the empty string,
+// lib/todoist within the current user's home directory is used.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+func (s *FileStore) path(name string) (string, error) {
+	dir := s.dir
+	if dir == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(u.HomeDir, "lib/todoist")
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Load implements Store.
+func (s *FileStore) Load() ([]byte, error) {
+	dataPath, err := s.path("state.data")
+	if err != nil {
+		return nil, err
+	}
+	sumPath, err := s.path("state.sum")
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	savedSum, err := ioutil.ReadFile(sumPath)
+	if err != nil {
+		return nil, err
+	}
+	err = verify(data, savedSum)
+	if err == nil {
+		return data, nil
+	}
+	// Save may have been interrupted after renaming the new data file into place, but before doing the same
+	// with the new checksum file. If so, complete the job.
+	if pendingSum, pendingErr := ioutil.ReadFile(sumPath + ".tmp"); pendingErr == nil && verify(data, pendingSum) == nil {
+		return data, os.Rename(sumPath+".tmp", sumPath)
+	}
+	return nil, err
+}
+
+func verify(data []byte, savedSum []byte) error {
+	sum := sha256.Sum256(data)
+	if len(savedSum) != len(sum) {
+		return fmt.Errorf("length mismatch: %w", ErrCorrupted)
+	}
+	for i := 0; i < len(sum); i++ {
+		if savedSum[i] != sum[i] {
+			return fmt.Errorf("checksum difference at byte %d: %w", i, ErrCorrupted)
+		}
+	}
+	return nil
+}
+
+// Save implements Store. Each file is replaced atomically, by writing a temporary file and renaming it, and the
+// data file is replaced before the checksum file, so that an interrupted Save can be recovered by Load.
+func (s *FileStore) Save(state []byte) error {
+	dataPath, err := s.path("state.data")
+	if err != nil {
+		return err
+	}
+	sumPath, err := s.path("state.sum")
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(state)
+	if err := writeFile(dataPath+".tmp", state); err != nil {
+		return err
+	}
+	if err := writeFile(sumPath+".tmp", sum[:]); err != nil {
+		return err
+	}
+	if err := os.Rename(dataPath+".tmp", dataPath); err != nil {
+		return err
+	}
+	return os.Rename(sumPath+".tmp", sumPath)
+}
+
+// writeFile is like ioutil.WriteFile, but also flushes the file to disk.
+func writeFile(pathname string, b []byte) error {
+	f, err := os.OpenFile(pathname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// MemoryStore keeps the state in memory. Useful for tests.
+type MemoryStore struct {
+	mu    sync.Mutex
+	state []byte
+}
+
+// NewMemoryStore creates an empty store.
+func NewMemoryStore() *MemoryStore {
+	return new(MemoryStore)
+}
+
+// Load implements Store.
+func (s *MemoryStore) Load() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == nil {
+		return nil, fmt.Errorf("memory store: %w", os.ErrNotExist)
+	}
+	return append([]byte(nil), s.state...), nil
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(state []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = append([]byte(nil), state...)
+	return nil
+}
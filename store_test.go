@@ -0,0 +1,102 @@
+package todoist_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "todoist")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	store := todoist.NewFileStore(dir)
+
+	_, err = store.Load()
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	require.Nil(t, store.Save([]byte("first")))
+	require.Nil(t, store.Save([]byte("second")))
+	b, err := store.Load()
+	require.Nil(t, err)
+	assert.Equal(t, "second", string(b))
+
+	// Simulate a crash after the data file was replaced, but before the checksum file was.
+	sum, err := ioutil.ReadFile(filepath.Join(dir, "state.sum"))
+	require.Nil(t, err)
+	require.Nil(t, store.Save([]byte("third")))
+	require.Nil(t, os.Rename(filepath.Join(dir, "state.sum"), filepath.Join(dir, "state.sum.tmp")))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "state.sum"), sum, 0600))
+	b, err = store.Load()
+	require.Nil(t, err)
+	assert.Equal(t, "third", string(b))
+	b, err = store.Load()
+	require.Nil(t, err)
+	assert.Equal(t, "third", string(b))
+
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "state.data"), []byte("tampered"), 0600))
+	_, err = store.Load()
+	assert.True(t, errors.Is(err, todoist.ErrCorrupted))
+}
+
+func TestDumpAndLoadRestoreQueuedCommands(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	store := todoist.NewMemoryStore()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL), todoist.WithStore(store))
+	require.Nil(t, err)
+	err = c.Load()
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	require.Nil(t, c.Pull())
+	tempID := c.QueueItemAdd(todoist.NewItemPatch(0).WithProjectID(10).WithContent("offline"))
+	c.QueueItemUpdate(todoist.NewItemPatch(1).WithContent("edited offline"))
+	require.Nil(t, c.Dump())
+
+	c, err = todoist.NewClient("token", todoist.WithEndpoint(fs.URL), todoist.WithStore(store))
+	require.Nil(t, err)
+	require.Nil(t, c.Load())
+	item, _ := c.ItemByID(1)
+	assert.Equal(t, "edited offline", item.Content)
+	assert.Len(t, c.SearchItems().WithContent("offline").Results(), 2)
+
+	require.Nil(t, c.Push())
+	assert.Equal(t, int64(2), atomic.LoadInt64(&fs.commands))
+	id, ok := c.PermanentID(tempID)
+	require.True(t, ok)
+	item, ok = c.ItemByID(id)
+	require.True(t, ok)
+	assert.Equal(t, "offline", item.Content)
+}
+
+func TestDumpAndLoadRestoreTemporaryIDs(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	store := todoist.NewMemoryStore()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(fs.URL), todoist.WithStore(store))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+	tempID := c.QueueItemAdd(todoist.NewItemPatch(0).WithProjectID(10).WithContent("pushed"))
+	require.Nil(t, c.Push())
+	id, ok := c.PermanentID(tempID)
+	require.True(t, ok)
+	// Queued referencing the item by its temporary id, resolved by the push above.
+	c.QueueNoteAdd(todoist.NewNotePatch(0).WithItemID(todoist.NewTemporaryID(tempID)).WithContent("queued"))
+	require.Nil(t, c.Dump())
+
+	c, err = todoist.NewClient("token", todoist.WithEndpoint(fs.URL), todoist.WithStore(store))
+	require.Nil(t, err)
+	require.Nil(t, c.Load())
+	restored, ok := c.PermanentID(tempID)
+	require.True(t, ok)
+	assert.Equal(t, id, restored)
+	notes := c.SearchNotes().WithItemID(id).Results()
+	require.Len(t, notes, 1)
+	assert.Equal(t, "queued", notes[0].Content)
+}
@@ -0,0 +1,223 @@
+package todoist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// These errors classify unsuccessful responses from the API. They're never returned directly, rather they are
+// wrapped in a *StatusError, so use errors.Is to check for them.
+var (
+	ErrAuth        = errors.New("authentication failed")
+	ErrRateLimited = errors.New("rate limited")
+	ErrServer      = errors.New("server error")
+	ErrBadRequest  = errors.New("bad request")
+)
+
+// StatusError is returned when the API responds with a status code other than 200 (after all retries, see
+// RetryPolicy). It wraps one of ErrAuth, ErrRateLimited, ErrServer, ErrBadRequest, and also matches
+// ErrStatusCode, for backwards compatibility.
+type StatusError struct {
+	Op         string        // E.g., "pull" or "push".
+	StatusCode int           // The HTTP status code.
+	Text       string        // The response body, which usually describes the error.
+	RetryAfter time.Duration // Zero unless the response had a Retry-After header.
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %d: %v: %s", e.Op, e.StatusCode, e.Unwrap(), e.Text)
+}
+
+// Unwrap returns the class of error, e.g., ErrRateLimited.
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuth
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrServer
+	case e.StatusCode >= 400:
+		return ErrBadRequest
+	default:
+		return ErrStatusCode
+	}
+}
+
+// Is makes errors.Is(err, ErrStatusCode) true for all status errors.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrStatusCode
+}
+
+func (e *StatusError) temporary() bool {
+	return errors.Is(e, ErrRateLimited) || errors.Is(e, ErrServer)
+}
+
+// RetryPolicy controls how remote calls are retried after a rate limit response (429), a server error (5xx), or
+// a network error. Delays between attempts grow exponentially with random jitter, and a delay requested by the
+// server via the Retry-After header is honored. Retrying pushes is safe, because each command carries a UUID
+// which the servers use to avoid executing the command twice.
+type RetryPolicy struct {
+	// Total number of attempts, including the first one. Values less than 2 disable retries.
+	MaxAttempts int
+
+	// The delay before the first retry. It doubles with each subsequent retry.
+	BaseDelay time.Duration
+
+	// Upper bound for the delay between any two attempts, including delays requested via Retry-After.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used unless a different one is passed to NewClient via WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// WithRetryPolicy is a client option to override DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) clientOption {
+	return func(c *Client) error {
+		c.retry = policy
+		return nil
+	}
+}
+
+// delay computes how long to wait before the given retry (the first retry is 1).
+func (policy RetryPolicy) delay(retry int, retryAfter time.Duration) time.Duration {
+	d := policy.BaseDelay << uint(retry-1)
+	if d <= 0 || d > policy.MaxDelay {
+		d = policy.MaxDelay
+	}
+	// Jitter, so that clients that failed together don't retry together.
+	if d > 0 {
+		d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	}
+	if retryAfter > d {
+		d = retryAfter
+	}
+	if d > policy.MaxDelay {
+		d = policy.MaxDelay
+	}
+	return d
+}
+
+// parseRetryAfter interprets the value of a Retry-After header, which is either a number of seconds or a date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// resolveEndpoint returns the URL of another endpoint of the API, relative to the sync endpoint, e.g.,
+// "projects/get_archived".
+func (c *Client) resolveEndpoint(ref string) (string, error) {
+	base, err := url.Parse(c.endpoint)
+	if err != nil {
+		return "", err
+	}
+	rel, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(rel).String(), nil
+}
+
+// post sends the form data to the given endpoint (usually the sync endpoint) and returns the response body,
+// retrying according to the client's retry policy. The op argument (e.g., "pull" or "push") is only used to
+// annotate errors and log entries.
+func (c *Client) post(ctx context.Context, op string, endpoint string, data url.Values) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		b, err := c.postOnce(ctx, op, endpoint, data)
+		if err == nil {
+			return b, nil
+		}
+		var retryAfter time.Duration
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			if !statusErr.temporary() {
+				return nil, err
+			}
+			retryAfter = statusErr.RetryAfter
+		}
+		if ctx.Err() != nil || attempt >= c.retry.MaxAttempts {
+			return nil, err
+		}
+		d := c.retry.delay(attempt, retryAfter)
+		log.WithFields(log.Fields{
+			"op":      op,
+			"attempt": attempt,
+			"delay":   d,
+			"cause":   err,
+		}).Warning("Retrying")
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, fmt.Errorf("%s: %w", op, ctx.Err())
+		case <-t.C:
+		}
+	}
+}
+
+func (c *Client) postOnce(ctx context.Context, op string, endpoint string, data url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			log.WithFields(log.Fields{
+				"op":    op,
+				"cause": err,
+			}).Warning("Could not close request body")
+		}
+	}()
+	b, err := ioutil.ReadAll(r.Body)
+	if r.StatusCode != http.StatusOK {
+		var responseText string
+		if err != nil {
+			responseText = fmt.Sprintf("unknown, because of error reading body: %v", err)
+		} else {
+			responseText = strings.TrimSpace(string(b))
+		}
+		return nil, &StatusError{
+			Op:         op,
+			StatusCode: r.StatusCode,
+			Text:       responseText,
+			RetryAfter: parseRetryAfter(r.Header.Get("Retry-After")),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s, read body: %w", op, err)
+	}
+	_, _ = c.wlog.Write([]byte(`{"type": "response", "response": `))
+	_, _ = c.wlog.Write(b)
+	_, _ = c.wlog.Write([]byte("}\n"))
+	return b, nil
+}
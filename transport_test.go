@@ -0,0 +1,86 @@
+package todoist_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetries = todoist.WithRetryPolicy(todoist.RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    10 * time.Millisecond,
+})
+
+// failingServer responds with the given status codes, in order, and then delegates to the next handler.
+func failingServer(next http.Handler, codes ...int) (*httptest.Server, *int64) {
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&requests, 1)
+		if int(n) <= len(codes) {
+			if codes[n-1] == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			http.Error(w, http.StatusText(codes[n-1]), codes[n-1])
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+	return ts, &requests
+}
+
+func TestRetryTemporaryErrors(t *testing.T) {
+	fs := newFakeServer()
+	defer fs.Close()
+	ts, requests := failingServer(http.HandlerFunc(fs.serveHTTP), http.StatusTooManyRequests, http.StatusBadGateway)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL), fastRetries)
+	require.Nil(t, err)
+	c.QueueItemClose(1)
+	require.Nil(t, c.Push())
+	assert.Equal(t, int64(3), atomic.LoadInt64(requests))
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	ts, requests := failingServer(http.NotFoundHandler(), 503, 503, 503, 503)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL), fastRetries)
+	require.Nil(t, err)
+	err = c.Pull()
+	assert.True(t, errors.Is(err, todoist.ErrServer))
+	assert.True(t, errors.Is(err, todoist.ErrStatusCode))
+	var statusErr *todoist.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, 503, statusErr.StatusCode)
+	assert.Equal(t, int64(3), atomic.LoadInt64(requests))
+}
+
+func TestNoRetryForPermanentErrors(t *testing.T) {
+	testCases := []struct {
+		code     int
+		expected error
+	}{
+		{http.StatusUnauthorized, todoist.ErrAuth},
+		{http.StatusForbidden, todoist.ErrAuth},
+		{http.StatusBadRequest, todoist.ErrBadRequest},
+		{http.StatusNotFound, todoist.ErrBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(http.StatusText(tc.code), func(t *testing.T) {
+			ts, requests := failingServer(http.NotFoundHandler(), tc.code)
+			defer ts.Close()
+			c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL), fastRetries)
+			require.Nil(t, err)
+			err = c.Pull()
+			assert.True(t, errors.Is(err, tc.expected))
+			assert.Equal(t, int64(1), atomic.LoadInt64(requests))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package todoist
+
+import "sort"
+
+// ItemNode is a node in a tree of items built by ItemTree.
+type ItemNode struct {
+	Item     *Item
+	Depth    int // Zero for the roots of the tree.
+	Children []*ItemNode
+}
+
+// Flatten lists the node and all its descendants, depth-first, i.e., in the order in which the apps show them.
+func (node *ItemNode) Flatten() []*ItemNode {
+	nodes := []*ItemNode{node}
+	for _, child := range node.Children {
+		nodes = append(nodes, child.Flatten()...)
+	}
+	return nodes
+}
+
+// ItemTree arranges the given items, e.g., the results of an ItemScan, into trees, according to their ParentID
+// property. Items whose parent is not among the given items are roots. Roots and children are sorted by their
+// ChildOrder property.
+func ItemTree(items []*Item) []*ItemNode {
+	nodes := make(map[int64]*ItemNode, len(items))
+	for _, item := range items {
+		nodes[item.ID] = &ItemNode{Item: item}
+	}
+	var roots []*ItemNode
+	for _, item := range items {
+		node := nodes[item.ID]
+		if parent, ok := nodes[item.ParentID]; ok && item.ParentID != 0 {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	sortItemNodes(roots, 0)
+	return roots
+}
+
+func sortItemNodes(nodes []*ItemNode, depth int) {
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i].Item, nodes[j].Item
+		if a.ChildOrder != b.ChildOrder {
+			return a.ChildOrder < b.ChildOrder
+		}
+		return a.ID < b.ID
+	})
+	for _, node := range nodes {
+		node.Depth = depth
+		sortItemNodes(node.Children, depth+1)
+	}
+}
+
+// ProjectNode is a node in a tree of projects built by ProjectTree.
+type ProjectNode struct {
+	Project  *Project
+	Depth    int // Zero for the roots of the tree.
+	Children []*ProjectNode
+}
+
+// Flatten is analogous to ItemNode.Flatten.
+func (node *ProjectNode) Flatten() []*ProjectNode {
+	nodes := []*ProjectNode{node}
+	for _, child := range node.Children {
+		nodes = append(nodes, child.Flatten()...)
+	}
+	return nodes
+}
+
+// ProjectTree is analogous to ItemTree.
+func ProjectTree(projects []*Project) []*ProjectNode {
+	nodes := make(map[int64]*ProjectNode, len(projects))
+	for _, project := range projects {
+		nodes[project.ID] = &ProjectNode{Project: project}
+	}
+	var roots []*ProjectNode
+	for _, project := range projects {
+		node := nodes[project.ID]
+		if parent, ok := nodes[project.ParentID]; ok && project.ParentID != 0 {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	sortProjectNodes(roots, 0)
+	return roots
+}
+
+func sortProjectNodes(nodes []*ProjectNode, depth int) {
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i].Project, nodes[j].Project
+		if a.ChildOrder != b.ChildOrder {
+			return a.ChildOrder < b.ChildOrder
+		}
+		return a.ID < b.ID
+	})
+	for _, node := range nodes {
+		node.Depth = depth
+		sortProjectNodes(node.Children, depth+1)
+	}
+}
+
+// Children returns the sub-tasks of the given item, sorted by their ChildOrder property.
+func (c *Client) Children(id int64) []*Item {
+	children := c.SearchItems().WithParentID(id).Results()
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].ChildOrder < children[j].ChildOrder
+	})
+	return children
+}
+
+// Parent returns the item the given item is a sub-task of, if any.
+func (c *Client) Parent(id int64) (*Item, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, ok := c.view.Items[id]
+	if !ok || item.ParentID == 0 {
+		return nil, false
+	}
+	parent, ok := c.view.Items[item.ParentID]
+	return parent, ok
+}
+
+// Ancestors returns the parent of the given item, the parent's parent, and so on.
+func (c *Client) Ancestors(id int64) []*Item {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var ancestors []*Item
+	for item, ok := c.view.Items[id]; ok && item.ParentID != 0; {
+		if item, ok = c.view.Items[item.ParentID]; ok {
+			ancestors = append(ancestors, item)
+		}
+		// Don't loop forever on inconsistent data.
+		if len(ancestors) > len(c.view.Items) {
+			break
+		}
+	}
+	return ancestors
+}
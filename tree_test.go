@@ -0,0 +1,102 @@
+package todoist_test
+
+import (
+	"testing"
+
+	"github.com/nicolagi/todoist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pullWithSubTasks = `{
+	"sync_token": "t",
+	"items": [
+		{"id": 1, "project_id": 10, "content": "root", "child_order": 2},
+		{"id": 2, "project_id": 10, "parent_id": 1, "content": "second child", "child_order": 2},
+		{"id": 3, "project_id": 10, "parent_id": 1, "content": "first child", "child_order": 1},
+		{"id": 4, "project_id": 10, "parent_id": 3, "content": "grandchild", "child_order": 1},
+		{"id": 5, "project_id": 10, "content": "other root", "child_order": 1},
+		{"id": 6, "project_id": 11, "section_id": 40, "content": "elsewhere", "child_order": 1}
+	],
+	"projects": [{"id": 10, "name": "Inbox"}, {"id": 11, "name": "Work"}]
+}`
+
+func TestItemTree(t *testing.T) {
+	ts := newStaticServer(pullWithSubTasks)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	roots := todoist.ItemTree(c.SearchItems().WithProjectID(10).Results())
+	require.Len(t, roots, 2)
+	var ids []int64
+	var depths []int
+	for _, root := range roots {
+		for _, node := range root.Flatten() {
+			ids = append(ids, node.Item.ID)
+			depths = append(depths, node.Depth)
+		}
+	}
+	assert.Equal(t, []int64{5, 1, 3, 4, 2}, ids)
+	assert.Equal(t, []int{0, 0, 1, 2, 1}, depths)
+
+	// Items whose parent is missing are roots.
+	roots = todoist.ItemTree(c.SearchItems().WithParentID(1).Results())
+	require.Len(t, roots, 2)
+	assert.Equal(t, int64(3), roots[0].Item.ID)
+	assert.Equal(t, 0, roots[0].Depth)
+}
+
+func TestClientHierarchy(t *testing.T) {
+	ts := newStaticServer(pullWithSubTasks)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	assert.Equal(t, []int64{3, 2}, itemIDs(c.Children(1)))
+	assert.Empty(t, c.Children(4))
+	parent, ok := c.Parent(4)
+	require.True(t, ok)
+	assert.Equal(t, int64(3), parent.ID)
+	_, ok = c.Parent(1)
+	assert.False(t, ok)
+	assert.Equal(t, []int64{3, 1}, itemIDs(c.Ancestors(4)))
+	assert.Empty(t, c.Ancestors(5))
+}
+
+func TestQueueItemMoveToParent(t *testing.T) {
+	ts := newStaticServer(pullWithSubTasks)
+	defer ts.Close()
+	c, err := todoist.NewClient("token", todoist.WithEndpoint(ts.URL))
+	require.Nil(t, err)
+	require.Nil(t, c.Pull())
+
+	// The sub-tree rooted at 3 follows its root under an item in another project and section.
+	c.QueueItemMoveToParent(todoist.NewID(3), todoist.NewID(6))
+	item, _ := c.ItemByID(3)
+	assert.Equal(t, int64(6), item.ParentID)
+	assert.Equal(t, int64(11), item.ProjectID)
+	assert.Equal(t, int64(40), item.SectionID)
+	item, _ = c.ItemByID(4)
+	assert.Equal(t, int64(3), item.ParentID)
+	assert.Equal(t, int64(11), item.ProjectID)
+	assert.Equal(t, int64(40), item.SectionID)
+
+	// Moving to a project makes the item a root.
+	c.QueueItemMove(todoist.NewID(3), todoist.NewID(10))
+	item, _ = c.ItemByID(3)
+	assert.Equal(t, int64(0), item.ParentID)
+	assert.Equal(t, int64(10), item.ProjectID)
+	assert.Equal(t, int64(0), item.SectionID)
+	item, _ = c.ItemByID(4)
+	assert.Equal(t, int64(10), item.ProjectID)
+
+	// Sub-tasks are deleted along with their parent.
+	c.QueueItemDelete(1)
+	_, ok := c.ItemByID(2)
+	assert.False(t, ok)
+	_, ok = c.ItemByID(3)
+	assert.True(t, ok)
+}
@@ -0,0 +1,80 @@
+package todoist
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// User partially describes the user the API token belongs to. Treat as read-only.
+type User struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	TZInfo   struct {
+		Timezone string `json:"timezone"` // E.g., Europe/Rome.
+	} `json:"tz_info"`
+}
+
+// WithTimezone is a client option to set the timezone used to interpret all-day and floating due dates, e.g.,
+// "Europe/Rome". By default, the timezone of the user's settings is used, or the local one before the first Pull.
+func WithTimezone(name string) clientOption {
+	return func(c *Client) error {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return err
+		}
+		c.location = loc
+		return nil
+	}
+}
+
+// User returns the user the API token belongs to. It returns false before the first Pull.
+func (c *Client) User() (*User, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.view.User, c.view.User != nil
+}
+
+// Location returns the user's timezone, see WithTimezone.
+func (c *Client) Location() *time.Location {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.locationLocked()
+}
+
+// locationLocked is like Location, for callers holding c.mu.
+func (c *Client) locationLocked() *time.Location {
+	if c.location != nil {
+		return c.location
+	}
+	if c.userLocation != nil {
+		return c.userLocation
+	}
+	return time.Local
+}
+
+// updateUser records the user and loads the location of their timezone. Must be called with c.mu held for
+// writing.
+func (c *Client) updateUser(user *User) {
+	if user == nil {
+		return
+	}
+	c.data.User = user
+	if c.userLocation != nil && c.userLocation.String() == user.TZInfo.Timezone {
+		return
+	}
+	c.userLocation = nil
+	if user.TZInfo.Timezone == "" {
+		return
+	}
+	loc, err := time.LoadLocation(user.TZInfo.Timezone)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"cause":    err,
+			"timezone": user.TZInfo.Timezone,
+		}).Warning("Could not load the user's timezone, using the local one")
+		return
+	}
+	c.userLocation = loc
+}